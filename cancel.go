@@ -0,0 +1,127 @@
+package pqerror
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// CancelCause is the cause of a canceled statement. QueryCanceled covers
+// statement timeouts, user requests and recovery conflicts, and
+// LockNotAvailable covers both lock timeouts and NOWAIT, so the cause is told
+// from the message of the error.
+type CancelCause int
+
+const (
+	// CancelNone is the cause of errors that are not cancellations.
+	CancelNone CancelCause = iota
+	// CancelUnknown is the cause of cancellations whose cause cannot be told,
+	// e.g. because the message is localized.
+	CancelUnknown
+	// CancelStatementTimeout is the cause of statements canceled because
+	// they ran longer than statement_timeout.
+	CancelStatementTimeout
+	// CancelLockTimeout is the cause of statements canceled because they
+	// waited for a lock longer than lock_timeout.
+	CancelLockTimeout
+	// CancelNoWait is the cause of statements failing because a lock
+	// requested with NOWAIT was not available.
+	CancelNoWait
+	// CancelUserRequest is the cause of statements canceled by a cancel
+	// request, e.g. by pg_cancel_backend.
+	CancelUserRequest
+	// CancelContextCanceled is the cause of statements canceled because their
+	// context was canceled, see CancelCauseContext.
+	CancelContextCanceled
+	// CancelContextDeadline is the cause of statements canceled because the
+	// deadline of their context passed, see CancelCauseContext.
+	CancelContextDeadline
+	// CancelRecoveryConflict is the cause of statements canceled on a hot
+	// standby because they conflicted with the replay of the primary.
+	CancelRecoveryConflict
+)
+
+var cancelCauseNames = [...]string{
+	CancelNone:             "none",
+	CancelUnknown:          "unknown",
+	CancelStatementTimeout: "statement_timeout",
+	CancelLockTimeout:      "lock_timeout",
+	CancelNoWait:           "nowait",
+	CancelUserRequest:      "user_request",
+	CancelContextCanceled:  "context_canceled",
+	CancelContextDeadline:  "context_deadline",
+	CancelRecoveryConflict: "recovery_conflict",
+}
+
+func (c CancelCause) String() string {
+	if c >= 0 && int(c) < len(cancelCauseNames) {
+		return cancelCauseNames[c]
+	}
+	return "CancelCause(" + strconv.Itoa(int(c)) + ")"
+}
+
+// CancelCauseOf returns the cause of a canceled statement from the code and
+// the message of an error. It returns CancelNone if there is no *pq.Error in
+// the chain of the error or if the error is not a cancellation.
+func CancelCauseOf(err error) CancelCause {
+	pqerr, ok := asPqError(err)
+	if !ok {
+		return CancelNone
+	}
+	msg := pqerr.Message
+	switch pqerr.Code {
+	case QueryCanceled:
+		switch {
+		case strings.HasPrefix(msg, "canceling statement due to statement timeout"):
+			return CancelStatementTimeout
+		case strings.HasPrefix(msg, "canceling statement due to user request"):
+			return CancelUserRequest
+		case strings.HasPrefix(msg, "canceling statement due to conflict with recovery"):
+			return CancelRecoveryConflict
+		}
+		return CancelUnknown
+	case LockNotAvailable:
+		if strings.HasPrefix(msg, "canceling statement due to lock timeout") {
+			return CancelLockTimeout
+		}
+		if strings.HasPrefix(msg, "could not obtain lock") {
+			return CancelNoWait
+		}
+		return CancelUnknown
+	case SerializationFailure, DatabaseDropped:
+		// Recovery conflicts are reported with these codes as well.
+		if strings.HasPrefix(msg, "canceling statement due to conflict with recovery") ||
+			strings.HasPrefix(msg, "terminating connection due to conflict with recovery") {
+			return CancelRecoveryConflict
+		}
+	}
+	return CancelNone
+}
+
+// CancelCauseContext is like CancelCauseOf but also correlates the error with
+// the context of the statement: pq cancels the statements whose context is
+// done, and the server reports them as canceled on user request. If so, or if
+// the error is context.Canceled or context.DeadlineExceeded, it returns
+// CancelContextCanceled or CancelContextDeadline.
+func CancelCauseContext(ctx context.Context, err error) CancelCause {
+	switch {
+	case err == nil:
+		return CancelNone
+	case errors.Is(err, context.DeadlineExceeded):
+		return CancelContextDeadline
+	case errors.Is(err, context.Canceled):
+		return CancelContextCanceled
+	}
+	cause := CancelCauseOf(err)
+	if ctx == nil || cause != CancelUserRequest && !(cause == CancelUnknown && Catches(err, QueryCanceled)) {
+		return cause
+	}
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return CancelContextDeadline
+	case context.Canceled:
+		return CancelContextCanceled
+	}
+	return cause
+}
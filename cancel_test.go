@@ -0,0 +1,107 @@
+package pqerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	statementTimeout = &pq.Error{Code: QueryCanceled, Message: "canceling statement due to statement timeout"}
+	userRequest      = &pq.Error{Code: QueryCanceled, Message: "canceling statement due to user request"}
+	// A QueryCanceled message of a server with lc_messages set to de_DE.
+	localizedCancel = &pq.Error{Code: QueryCanceled, Message: "storniere Anfrage wegen Benutzeranforderung"}
+)
+
+func TestCancelCauseOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want CancelCause
+	}{
+		{"nil", nil, CancelNone},
+		{"non-pq error", errors.New("canceling statement due to user request"), CancelNone},
+		{"other code", &pq.Error{Code: UniqueViolation, Message: "canceling statement due to user request"}, CancelNone},
+		{"statement timeout", statementTimeout, CancelStatementTimeout},
+		{"wrapped statement timeout", fmt.Errorf("report: %w", statementTimeout), CancelStatementTimeout},
+		{"user request", userRequest, CancelUserRequest},
+		{"lock timeout", &pq.Error{Code: LockNotAvailable, Message: "canceling statement due to lock timeout"}, CancelLockTimeout},
+		{"nowait", &pq.Error{Code: LockNotAvailable, Message: `could not obtain lock on row in relation "accounts"`}, CancelNoWait},
+		{"localized lock", &pq.Error{Code: LockNotAvailable, Message: "konnte Sperre nicht setzen"}, CancelUnknown},
+		{"localized", localizedCancel, CancelUnknown},
+		{
+			"recovery conflict query canceled",
+			&pq.Error{Code: QueryCanceled, Message: "canceling statement due to conflict with recovery"},
+			CancelRecoveryConflict,
+		},
+		{
+			"recovery conflict serialization failure",
+			&pq.Error{Code: SerializationFailure, Message: "canceling statement due to conflict with recovery"},
+			CancelRecoveryConflict,
+		},
+		{
+			"recovery conflict database dropped",
+			&pq.Error{Code: DatabaseDropped, Message: "terminating connection due to conflict with recovery"},
+			CancelRecoveryConflict,
+		},
+		{
+			"serialization failure",
+			&pq.Error{Code: SerializationFailure, Message: "could not serialize access due to concurrent update"},
+			CancelNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CancelCauseOf(tt.err); got != tt.want {
+				t.Errorf("CancelCauseOf(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelCauseContext(t *testing.T) {
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	live := context.Background()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want CancelCause
+	}{
+		{"nil error", expired, nil, CancelNone},
+		{"user request with expired context", expired, userRequest, CancelContextDeadline},
+		{"user request with canceled context", canceled, fmt.Errorf("report: %w", userRequest), CancelContextCanceled},
+		{"user request with live context", live, userRequest, CancelUserRequest},
+		{"user request without context", nil, userRequest, CancelUserRequest},
+		{"localized with expired context", expired, localizedCancel, CancelContextDeadline},
+		{"localized with live context", live, localizedCancel, CancelUnknown},
+		{"statement timeout with expired context", expired, statementTimeout, CancelStatementTimeout},
+		{"lock timeout with canceled context", canceled, &pq.Error{Code: LockNotAvailable, Message: "canceling statement due to lock timeout"}, CancelLockTimeout},
+		{"deadline exceeded", live, fmt.Errorf("query: %w", context.DeadlineExceeded), CancelContextDeadline},
+		{"canceled", live, context.Canceled, CancelContextCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CancelCauseContext(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("CancelCauseContext(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelCauseString(t *testing.T) {
+	if got := CancelNoWait.String(); got != "nowait" {
+		t.Errorf("String() = %q, want nowait", got)
+	}
+	if got := CancelCause(42).String(); got != "CancelCause(42)" {
+		t.Errorf("String() = %q, want CancelCause(42)", got)
+	}
+}
@@ -0,0 +1,561 @@
+package pqerror
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// CodeInfo describes a single PostgreSQL error code.
+type CodeInfo struct {
+	// Code is the five-character SQLSTATE, e.g. "23505".
+	Code pq.ErrorCode
+	// Condition is the condition name used by PL/pgSQL, e.g. "unique_violation".
+	Condition string
+	// Constant is the name of the exported Go constant, e.g. "UniqueViolation".
+	Constant string
+	// Description explains when the code is raised, e.g. "A row duplicates
+	// the key of another row in a unique index or a primary key."
+	Description string
+}
+
+// Class returns the class of the code.
+func (i CodeInfo) Class() pq.ErrorClass {
+	return i.Code.Class()
+}
+
+// ClassInfo describes a PostgreSQL error class.
+type ClassInfo struct {
+	// Class is the two-character class, e.g. "23".
+	Class pq.ErrorClass
+	// Title is the class title used by the PostgreSQL documentation.
+	Title string
+	// Constant is the name of the exported Go constant, e.g. "ClassIntegrityConstraintViolation".
+	Constant string
+}
+
+// Lookup returns the catalog entry of a given code, which may be a code
+// registered by Register.
+func Lookup(code pq.ErrorCode) (CodeInfo, bool) {
+	if i, ok := codeIndex[code]; ok {
+		return codeCatalog[i], true
+	}
+	if c, ok := LookupCustom(code); ok {
+		return c.info(), true
+	}
+	return CodeInfo{}, false
+}
+
+// LookupCondition returns the catalog entries of a given condition name.
+// A few condition names are shared by several codes (e.g.
+// "string_data_right_truncation" is both 01004 and 22001), hence the slice.
+func LookupCondition(name string) []CodeInfo {
+	name = strings.ToLower(name)
+	var infos []CodeInfo
+	for _, i := range conditionIndex[name] {
+		infos = append(infos, codeCatalog[i])
+	}
+	if c, ok := lookupCustomCondition(name); ok {
+		infos = append(infos, c.info())
+	}
+	return infos
+}
+
+// LookupConstant returns the catalog entry of a given Go constant name,
+// e.g. "UniqueViolation".
+func LookupConstant(name string) (CodeInfo, bool) {
+	if i, ok := constantIndex[name]; ok {
+		return codeCatalog[i], true
+	}
+	if code, ok := constantAliases[name]; ok {
+		return Lookup(code)
+	}
+	return CodeInfo{}, false
+}
+
+// ConditionName returns the condition name of a code, e.g.
+// "unique_violation" for UniqueViolation, or an empty string if the code is
+// unknown.
+func ConditionName(code pq.ErrorCode) string {
+	info, _ := Lookup(code)
+	return info.Condition
+}
+
+// ParseConditionName returns the codes of a condition name, e.g.
+// UniqueViolation for "unique_violation". It returns an error if the name is
+// unknown. A few condition names are shared by several codes, see
+// LookupCondition.
+func ParseConditionName(name string) ([]pq.ErrorCode, error) {
+	infos := LookupCondition(name)
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("pqerror: unknown condition name %q", name)
+	}
+	codes := make([]pq.ErrorCode, len(infos))
+	for i, info := range infos {
+		codes[i] = info.Code
+	}
+	return codes, nil
+}
+
+// GoName returns the name of the exported constant of a code, e.g.
+// "UniqueViolation" for 23505, or an empty string if there is none.
+func GoName(code pq.ErrorCode) string {
+	info, _ := Lookup(code)
+	return info.Constant
+}
+
+// LookupClass returns the catalog entry of a given class.
+func LookupClass(class pq.ErrorClass) (ClassInfo, bool) {
+	for _, info := range classCatalog {
+		if info.Class == class {
+			return info, true
+		}
+	}
+	return ClassInfo{}, false
+}
+
+// Codes returns all known codes in the catalog order.
+func Codes() []CodeInfo {
+	return append([]CodeInfo(nil), codeCatalog...)
+}
+
+// ClassCodes returns all known codes of a given class.
+func ClassCodes(class pq.ErrorClass) []CodeInfo {
+	var infos []CodeInfo
+	for _, info := range codeCatalog {
+		if info.Code.Class() == class {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// Classes returns all known classes in the catalog order.
+func Classes() []ClassInfo {
+	return append([]ClassInfo(nil), classCatalog...)
+}
+
+var (
+	codeCatalog    []CodeInfo
+	codeIndex      = make(map[pq.ErrorCode]int)
+	conditionIndex = make(map[string][]int)
+	constantIndex  = make(map[string]int)
+)
+
+func init() {
+	for _, e := range codeEntries {
+		i := len(codeCatalog)
+		codeCatalog = append(codeCatalog, CodeInfo{
+			Code:        e.code,
+			Condition:   e.condition,
+			Constant:    e.constant,
+			Description: codeDescriptions[e.code],
+		})
+		codeIndex[e.code] = i
+		conditionIndex[e.condition] = append(conditionIndex[e.condition], i)
+		constantIndex[e.constant] = i
+	}
+}
+
+// constantAliases lists the exported constants that share their code with
+// another constant and thus have no catalog entry of their own.
+var constantAliases = map[string]pq.ErrorCode{
+	"ArrayElementError":       ArrayElementError,
+	"DatetimeValueOutOfRange": DatetimeValueOutOfRange,
+}
+
+var classCatalog = []ClassInfo{
+	{ClassSuccessfulCompletion, "Successful Completion", "ClassSuccessfulCompletion"},
+	{ClassWarning, "Warning", "ClassWarning"},
+	{ClassNoData, "No Data", "ClassNoData"},
+	{ClassSQLStatementNotYetComplete, "SQL Statement Not Yet Complete", "ClassSQLStatementNotYetComplete"},
+	{ClassConnectionException, "Connection Exception", "ClassConnectionException"},
+	{ClassTriggeredActionException, "Triggered Action Exception", "ClassTriggeredActionException"},
+	{ClassFeatureNotSupported, "Feature Not Supported", "ClassFeatureNotSupported"},
+	{ClassInvalidTransactionInitiation, "Invalid Transaction Initiation", "ClassInvalidTransactionInitiation"},
+	{ClassLocatorException, "Locator Exception", "ClassLocatorException"},
+	{ClassInvalidGrantor, "Invalid Grantor", "ClassInvalidGrantor"},
+	{ClassInvalidRoleSpecification, "Invalid Role Specification", "ClassInvalidRoleSpecification"},
+	{ClassDiagnosticsException, "Diagnostics Exception", "ClassDiagnosticsException"},
+	{ClassCaseNotFound, "Case Not Found", "ClassCaseNotFound"},
+	{ClassCardinalityViolation, "Cardinality Violation", "ClassCardinalityViolation"},
+	{ClassDataException, "Data Exception", "ClassDataException"},
+	{ClassIntegrityConstraintViolation, "Integrity Constraint Violation", "ClassIntegrityConstraintViolation"},
+	{ClassInvalidCursorState, "Invalid Cursor State", "ClassInvalidCursorState"},
+	{ClassInvalidTransactionState, "Invalid Transaction State", "ClassInvalidTransactionState"},
+	{ClassInvalidSQLStatementName, "Invalid SQL Statement Name", "ClassInvalidSQLStatementName"},
+	{ClassTriggeredDataChangeViolation, "Triggered Data Change Violation", "ClassTriggeredDataChangeViolation"},
+	{ClassInvalidAuthorizationSpecification, "Invalid Authorization Specification", "ClassInvalidAuthorizationSpecification"},
+	{ClassDependentPrivilegeDescriptorsStillExist, "Dependent Privilege Descriptors Still Exist", "ClassDependentPrivilegeDescriptorsStillExist"},
+	{ClassInvalidTransactionTermination, "Invalid Transaction Termination", "ClassInvalidTransactionTermination"},
+	{ClassSQLRoutineException, "SQL Routine Exception", "ClassSQLRoutineException"},
+	{ClassInvalidCursorName, "Invalid Cursor Name", "ClassInvalidCursorName"},
+	{ClassExternalRoutineException, "External Routine Exception", "ClassExternalRoutineException"},
+	{ClassExternalRoutineInvocationException, "External Routine Invocation Exception", "ClassExternalRoutineInvocationException"},
+	{ClassSavepointException, "Savepoint Exception", "ClassSavepointException"},
+	{ClassInvalidCatalogName, "Invalid Catalog Name", "ClassInvalidCatalogName"},
+	{ClassInvalidSchemaName, "Invalid Schema Name", "ClassInvalidSchemaName"},
+	{ClassTransactionRollback, "Transaction Rollback", "ClassTransactionRollback"},
+	{ClassSyntaxErrorOrAccessRuleViolation, "Syntax Error or Access Rule Violation", "ClassSyntaxErrorOrAccessRuleViolation"},
+	{ClassWithCheckOptionViolation, "WITH CHECK OPTION Violation", "ClassWithCheckOptionViolation"},
+	{ClassInsufficientResources, "Insufficient Resources", "ClassInsufficientResources"},
+	{ClassProgramLimitExceeded, "Program Limit Exceeded", "ClassProgramLimitExceeded"},
+	{ClassObjectNotInPrerequisiteState, "Object Not In Prerequisite State", "ClassObjectNotInPrerequisiteState"},
+	{ClassOperatorIntervention, "Operator Intervention", "ClassOperatorIntervention"},
+	{ClassSystemError, "System Error", "ClassSystemError"},
+	{ClassSnapshotTooOld, "Snapshot Failure", "ClassSnapshotTooOld"},
+	{ClassConfigFileError, "Configuration File Error", "ClassConfigFileError"},
+	{ClassFdwError, "Foreign Data Wrapper Error", "ClassFdwError"},
+	{ClassPlpgsqlError, "PL/pgSQL Error", "ClassPlpgsqlError"},
+	{ClassInternalError, "Internal Error", "ClassInternalError"},
+}
+
+type codeEntry struct {
+	code      pq.ErrorCode
+	condition string
+	constant  string
+}
+
+// See https://github.com/postgres/postgres/blob/REL_12_STABLE/src/backend/utils/errcodes.txt.
+var codeEntries = []codeEntry{
+	// Class 00 - Successful Completion
+	{SuccessfulCompletion, "successful_completion", "SuccessfulCompletion"},
+
+	// Class 01 - Warning
+	{Warning, "warning", "Warning"},
+	{WarningDynamicResultSetsReturned, "dynamic_result_sets_returned", "WarningDynamicResultSetsReturned"},
+	{WarningImplicitZeroBitPadding, "implicit_zero_bit_padding", "WarningImplicitZeroBitPadding"},
+	{WarningNullValueEliminatedInSetFunction, "null_value_eliminated_in_set_function", "WarningNullValueEliminatedInSetFunction"},
+	{WarningPrivilegeNotGranted, "privilege_not_granted", "WarningPrivilegeNotGranted"},
+	{WarningPrivilegeNotRevoked, "privilege_not_revoked", "WarningPrivilegeNotRevoked"},
+	{WarningStringDataRightTruncation, "string_data_right_truncation", "WarningStringDataRightTruncation"},
+	{WarningDeprecatedFeature, "deprecated_feature", "WarningDeprecatedFeature"},
+
+	// Class 02 - No Data
+	{NoData, "no_data", "NoData"},
+	{NoAdditionalDynamicResultSetsReturned, "no_additional_dynamic_result_sets_returned", "NoAdditionalDynamicResultSetsReturned"},
+
+	// Class 03 - SQL Statement Not Yet Complete
+	{SQLStatementNotYetComplete, "sql_statement_not_yet_complete", "SQLStatementNotYetComplete"},
+
+	// Class 08 - Connection Exception
+	{ConnectionException, "connection_exception", "ConnectionException"},
+	{ConnectionDoesNotExist, "connection_does_not_exist", "ConnectionDoesNotExist"},
+	{ConnectionFailure, "connection_failure", "ConnectionFailure"},
+	{SQLClientUnableToEstablishSQLConnection, "sqlclient_unable_to_establish_sqlconnection", "SQLClientUnableToEstablishSQLConnection"},
+	{SQLServerRejectedEstablishmentOfSQLConnection, "sqlserver_rejected_establishment_of_sqlconnection", "SQLServerRejectedEstablishmentOfSQLConnection"},
+	{TransactionResolutionUnknown, "transaction_resolution_unknown", "TransactionResolutionUnknown"},
+	{ProtocolViolation, "protocol_violation", "ProtocolViolation"},
+
+	// Class 09 - Triggered Action Exception
+	{TriggeredActionException, "triggered_action_exception", "TriggeredActionException"},
+
+	// Class 0A - Feature Not Supported
+	{FeatureNotSupported, "feature_not_supported", "FeatureNotSupported"},
+
+	// Class 0B - Invalid Transaction Initiation
+	{InvalidTransactionInitiation, "invalid_transaction_initiation", "InvalidTransactionInitiation"},
+
+	// Class 0F - Locator Exception
+	{LocatorException, "locator_exception", "LocatorException"},
+	{InvalidLocatorSpecification, "invalid_locator_specification", "InvalidLocatorSpecification"},
+
+	// Class 0L - Invalid Grantor
+	{InvalidGrantor, "invalid_grantor", "InvalidGrantor"},
+	{InvalidGrantOperation, "invalid_grant_operation", "InvalidGrantOperation"},
+
+	// Class 0P - Invalid Role Specification
+	{InvalidRoleSpecification, "invalid_role_specification", "InvalidRoleSpecification"},
+
+	// Class 0Z - Diagnostics Exception
+	{DiagnosticsException, "diagnostics_exception", "DiagnosticsException"},
+	{StackedDiagnosticsAccessedWithoutActiveHandler, "stacked_diagnostics_accessed_without_active_handler", "StackedDiagnosticsAccessedWithoutActiveHandler"},
+
+	// Class 20 - Case Not Found
+	{CaseNotFound, "case_not_found", "CaseNotFound"},
+
+	// Class 21 - Cardinality Violation
+	{CardinalityViolation, "cardinality_violation", "CardinalityViolation"},
+
+	// Class 22 - Data Exception
+	{DataException, "data_exception", "DataException"},
+	{ArraySubscriptError, "array_subscript_error", "ArraySubscriptError"},
+	{CharacterNotInRepertoire, "character_not_in_repertoire", "CharacterNotInRepertoire"},
+	{DatetimeFieldOverflow, "datetime_field_overflow", "DatetimeFieldOverflow"},
+	{DivisionByZero, "division_by_zero", "DivisionByZero"},
+	{ErrorInAssignment, "error_in_assignment", "ErrorInAssignment"},
+	{EscapeCharacterConflict, "escape_character_conflict", "EscapeCharacterConflict"},
+	{IndicatorOverflow, "indicator_overflow", "IndicatorOverflow"},
+	{IntervalFieldOverflow, "interval_field_overflow", "IntervalFieldOverflow"},
+	{InvalidArgumentForLogarithm, "invalid_argument_for_logarithm", "InvalidArgumentForLogarithm"},
+	{InvalidArgumentForNtileFunction, "invalid_argument_for_ntile_function", "InvalidArgumentForNtileFunction"},
+	{InvalidArgumentForNthValueFunction, "invalid_argument_for_nth_value_function", "InvalidArgumentForNthValueFunction"},
+	{InvalidArgumentForPowerFunction, "invalid_argument_for_power_function", "InvalidArgumentForPowerFunction"},
+	{InvalidArgumentForWidthBucketFunction, "invalid_argument_for_width_bucket_function", "InvalidArgumentForWidthBucketFunction"},
+	{InvalidCharacterValueForCast, "invalid_character_value_for_cast", "InvalidCharacterValueForCast"},
+	{InvalidDatetimeFormat, "invalid_datetime_format", "InvalidDatetimeFormat"},
+	{InvalidEscapeCharacter, "invalid_escape_character", "InvalidEscapeCharacter"},
+	{InvalidEscapeOctet, "invalid_escape_octet", "InvalidEscapeOctet"},
+	{InvalidEscapeSequence, "invalid_escape_sequence", "InvalidEscapeSequence"},
+	{NonstandardUseOfEscapeCharacter, "nonstandard_use_of_escape_character", "NonstandardUseOfEscapeCharacter"},
+	{InvalidIndicatorParameterValue, "invalid_indicator_parameter_value", "InvalidIndicatorParameterValue"},
+	{InvalidParameterValue, "invalid_parameter_value", "InvalidParameterValue"},
+	{InvalidRegularExpression, "invalid_regular_expression", "InvalidRegularExpression"},
+	{InvalidRowCountInLimitClause, "invalid_row_count_in_limit_clause", "InvalidRowCountInLimitClause"},
+	{InvalidRowCountInResultOffsetClause, "invalid_row_count_in_result_offset_clause", "InvalidRowCountInResultOffsetClause"},
+	{InvalidTablesampleArgument, "invalid_tablesample_argument", "InvalidTablesampleArgument"},
+	{InvalidTablesampleRepeat, "invalid_tablesample_repeat", "InvalidTablesampleRepeat"},
+	{InvalidTimeZoneDisplacementValue, "invalid_time_zone_displacement_value", "InvalidTimeZoneDisplacementValue"},
+	{InvalidUseOfEscapeCharacter, "invalid_use_of_escape_character", "InvalidUseOfEscapeCharacter"},
+	{MostSpecificTypeMismatch, "most_specific_type_mismatch", "MostSpecificTypeMismatch"},
+	{NullValueNotAllowed, "null_value_not_allowed", "NullValueNotAllowed"},
+	{NullValueNoIndicatorParameter, "null_value_no_indicator_parameter", "NullValueNoIndicatorParameter"},
+	{NumericValueOutOfRange, "numeric_value_out_of_range", "NumericValueOutOfRange"},
+	{StringDataLengthMismatch, "string_data_length_mismatch", "StringDataLengthMismatch"},
+	{StringDataRightTruncation, "string_data_right_truncation", "StringDataRightTruncation"},
+	{SubstringError, "substring_error", "SubstringError"},
+	{TrimError, "trim_error", "TrimError"},
+	{UnterminatedCString, "unterminated_c_string", "UnterminatedCString"},
+	{ZeroLengthCharacterString, "zero_length_character_string", "ZeroLengthCharacterString"},
+	{FloatingPointException, "floating_point_exception", "FloatingPointException"},
+	{InvalidTextRepresentation, "invalid_text_representation", "InvalidTextRepresentation"},
+	{InvalidBinaryRepresentation, "invalid_binary_representation", "InvalidBinaryRepresentation"},
+	{BadCopyFileFormat, "bad_copy_file_format", "BadCopyFileFormat"},
+	{UntranslatableCharacter, "untranslatable_character", "UntranslatableCharacter"},
+	{NotAnXmlDocument, "not_an_xml_document", "NotAnXmlDocument"},
+	{InvalidXmlDocument, "invalid_xml_document", "InvalidXmlDocument"},
+	{InvalidXmlContent, "invalid_xml_content", "InvalidXmlContent"},
+	{InvalidXmlComment, "invalid_xml_comment", "InvalidXmlComment"},
+	{InvalidXmlProcessingInstruction, "invalid_xml_processing_instruction", "InvalidXmlProcessingInstruction"},
+	{DuplicateJsonObjectKeyValue, "duplicate_json_object_key_value", "DuplicateJsonObjectKeyValue"},
+	{InvalidJsonText, "invalid_json_text", "InvalidJsonText"},
+	{InvalidJsonSubscript, "invalid_sql_json_subscript", "InvalidJsonSubscript"},
+	{MoreThanOneJsonItem, "more_than_one_sql_json_item", "MoreThanOneJsonItem"},
+	{NoJsonItem, "no_sql_json_item", "NoJsonItem"},
+	{NonNumericJsonItem, "non_numeric_sql_json_item", "NonNumericJsonItem"},
+	{NonUniqueKeysInJsonObject, "non_unique_keys_in_a_json_object", "NonUniqueKeysInJsonObject"},
+	{SingletonJsonItemRequired, "singleton_sql_json_item_required", "SingletonJsonItemRequired"},
+	{JsonArrayNotFound, "sql_json_array_not_found", "JsonArrayNotFound"},
+	{JsonMemberNotFound, "sql_json_member_not_found", "JsonMemberNotFound"},
+	{JsonNumberNotFound, "sql_json_number_not_found", "JsonNumberNotFound"},
+	{JsonObjectNotFound, "sql_json_object_not_found", "JsonObjectNotFound"},
+	{JsonScalarRequired, "sql_json_scalar_required", "JsonScalarRequired"},
+	{TooManyJsonArrayElements, "too_many_json_array_elements", "TooManyJsonArrayElements"},
+	{TooManyJsonObjectMembers, "too_many_json_object_members", "TooManyJsonObjectMembers"},
+
+	// Class 23 - Integrity Constraint Violation
+	{IntegrityConstraintViolation, "integrity_constraint_violation", "IntegrityConstraintViolation"},
+	{RestrictViolation, "restrict_violation", "RestrictViolation"},
+	{NotNullViolation, "not_null_violation", "NotNullViolation"},
+	{ForeignKeyViolation, "foreign_key_violation", "ForeignKeyViolation"},
+	{UniqueViolation, "unique_violation", "UniqueViolation"},
+	{CheckViolation, "check_violation", "CheckViolation"},
+	{ExclusionViolation, "exclusion_violation", "ExclusionViolation"},
+
+	// Class 24 - Invalid Cursor State
+	{InvalidCursorState, "invalid_cursor_state", "InvalidCursorState"},
+
+	// Class 25 - Invalid Transaction State
+	{InvalidTransactionState, "invalid_transaction_state", "InvalidTransactionState"},
+	{ActiveSQLTransaction, "active_sql_transaction", "ActiveSQLTransaction"},
+	{BranchTransactionAlreadyActive, "branch_transaction_already_active", "BranchTransactionAlreadyActive"},
+	{HeldCursorRequiresSameIsolationLevel, "held_cursor_requires_same_isolation_level", "HeldCursorRequiresSameIsolationLevel"},
+	{InappropriateAccessModeForBranchTransaction, "inappropriate_access_mode_for_branch_transaction", "InappropriateAccessModeForBranchTransaction"},
+	{InappropriateIsolationLevelForBranchTransaction, "inappropriate_isolation_level_for_branch_transaction", "InappropriateIsolationLevelForBranchTransaction"},
+	{NoActiveSQLTransactionForBranchTransaction, "no_active_sql_transaction_for_branch_transaction", "NoActiveSQLTransactionForBranchTransaction"},
+	{ReadOnlySQLTransaction, "read_only_sql_transaction", "ReadOnlySQLTransaction"},
+	{SchemaAndDataStatementMixingNotSupported, "schema_and_data_statement_mixing_not_supported", "SchemaAndDataStatementMixingNotSupported"},
+	{NoActiveSQLTransaction, "no_active_sql_transaction", "NoActiveSQLTransaction"},
+	{InFailedSQLTransaction, "in_failed_sql_transaction", "InFailedSQLTransaction"},
+	{IdleInTransactionSessionTimeout, "idle_in_transaction_session_timeout", "IdleInTransactionSessionTimeout"},
+
+	// Class 26 - Invalid SQL Statement Name
+	{InvalidSQLStatementName, "invalid_sql_statement_name", "InvalidSQLStatementName"},
+
+	// Class 27 - Triggered Data Change Violation
+	{TriggeredDataChangeViolation, "triggered_data_change_violation", "TriggeredDataChangeViolation"},
+
+	// Class 28 - Invalid Authorization Specification
+	{InvalidAuthorizationSpecification, "invalid_authorization_specification", "InvalidAuthorizationSpecification"},
+	{InvalidPassword, "invalid_password", "InvalidPassword"},
+
+	// Class 2B - Dependent Privilege Descriptors Still Exist
+	{DependentPrivilegeDescriptorsStillExist, "dependent_privilege_descriptors_still_exist", "DependentPrivilegeDescriptorsStillExist"},
+	{DependentObjectsStillExist, "dependent_objects_still_exist", "DependentObjectsStillExist"},
+
+	// Class 2D - Invalid Transaction Termination
+	{InvalidTransactionTermination, "invalid_transaction_termination", "InvalidTransactionTermination"},
+
+	// Class 2F - SQL Routine Exception
+	{SQLRoutineException, "sql_routine_exception", "SQLRoutineException"},
+	{FunctionExecutedNoReturnStatement, "function_executed_no_return_statement", "FunctionExecutedNoReturnStatement"},
+	{ModifyingSQLDataNotPermitted, "modifying_sql_data_not_permitted", "ModifyingSQLDataNotPermitted"},
+	{ProhibitedSQLStatementAttempted, "prohibited_sql_statement_attempted", "ProhibitedSQLStatementAttempted"},
+	{ReadingSQLDataNotPermitted, "reading_sql_data_not_permitted", "ReadingSQLDataNotPermitted"},
+
+	// Class 34 - Invalid Cursor Name
+	{InvalidCursorName, "invalid_cursor_name", "InvalidCursorName"},
+
+	// Class 38 - External Routine Exception
+	{ExternalRoutineException, "external_routine_exception", "ExternalRoutineException"},
+	{ExternalRoutineException_ContainingSQLNotPermitted, "containing_sql_not_permitted", "ExternalRoutineException_ContainingSQLNotPermitted"},
+	{ExternalRoutineException_ModifyingSQLDataNotPermitted, "modifying_sql_data_not_permitted", "ExternalRoutineException_ModifyingSQLDataNotPermitted"},
+	{ExternalRoutineException_ProhibitedSQLStatementAttempted, "prohibited_sql_statement_attempted", "ExternalRoutineException_ProhibitedSQLStatementAttempted"},
+	{ExternalRoutineException_ReadingSQLDataNotPermitted, "reading_sql_data_not_permitted", "ExternalRoutineException_ReadingSQLDataNotPermitted"},
+
+	// Class 39 - External Routine Invocation Exception
+	{ExternalRoutineInvocationException, "external_routine_invocation_exception", "ExternalRoutineInvocationException"},
+	{ExternalRoutineInvocationException_InvalidSQLstateReturned, "invalid_sqlstate_returned", "ExternalRoutineInvocationException_InvalidSQLstateReturned"},
+	{ExternalRoutineInvocationException_NullValueNotAllowed, "null_value_not_allowed", "ExternalRoutineInvocationException_NullValueNotAllowed"},
+	{ExternalRoutineInvocationException_TriggerProtocolViolated, "trigger_protocol_violated", "ExternalRoutineInvocationException_TriggerProtocolViolated"},
+	{ExternalRoutineInvocationException_SrfProtocolViolated, "srf_protocol_violated", "ExternalRoutineInvocationException_SrfProtocolViolated"},
+	{ExternalRoutineInvocationException_EventTriggerProtocolViolated, "event_trigger_protocol_violated", "ExternalRoutineInvocationException_EventTriggerProtocolViolated"},
+
+	// Class 3B - Savepoint Exception
+	{SavepointException, "savepoint_exception", "SavepointException"},
+	{InvalidSavepointSpecification, "invalid_savepoint_specification", "InvalidSavepointSpecification"},
+
+	// Class 3D - Invalid Catalog Name
+	{InvalidCatalogName, "invalid_catalog_name", "InvalidCatalogName"},
+
+	// Class 3F - Invalid Schema Name
+	{InvalidSchemaName, "invalid_schema_name", "InvalidSchemaName"},
+
+	// Class 40 - Transaction Rollback
+	{TransactionRollback, "transaction_rollback", "TransactionRollback"},
+	{TransactionIntegrityConstraintViolation, "transaction_integrity_constraint_violation", "TransactionIntegrityConstraintViolation"},
+	{SerializationFailure, "serialization_failure", "SerializationFailure"},
+	{StatementCompletionUnknown, "statement_completion_unknown", "StatementCompletionUnknown"},
+	{DeadlockDetected, "deadlock_detected", "DeadlockDetected"},
+
+	// Class 42 - Syntax Error or Access Rule Violation
+	{SyntaxErrorOrAccessRuleViolation, "syntax_error_or_access_rule_violation", "SyntaxErrorOrAccessRuleViolation"},
+	{SyntaxError, "syntax_error", "SyntaxError"},
+	{InsufficientPrivilege, "insufficient_privilege", "InsufficientPrivilege"},
+	{CannotCoerce, "cannot_coerce", "CannotCoerce"},
+	{GroupingError, "grouping_error", "GroupingError"},
+	{WindowingError, "windowing_error", "WindowingError"},
+	{InvalidRecursion, "invalid_recursion", "InvalidRecursion"},
+	{InvalidForeignKey, "invalid_foreign_key", "InvalidForeignKey"},
+	{InvalidName, "invalid_name", "InvalidName"},
+	{NameTooLong, "name_too_long", "NameTooLong"},
+	{ReservedName, "reserved_name", "ReservedName"},
+	{DatatypeMismatch, "datatype_mismatch", "DatatypeMismatch"},
+	{IndeterminateDatatype, "indeterminate_datatype", "IndeterminateDatatype"},
+	{CollationMismatch, "collation_mismatch", "CollationMismatch"},
+	{IndeterminateCollation, "indeterminate_collation", "IndeterminateCollation"},
+	{WrongObjectType, "wrong_object_type", "WrongObjectType"},
+	{UndefinedColumn, "undefined_column", "UndefinedColumn"},
+	{UndefinedFunction, "undefined_function", "UndefinedFunction"},
+	{UndefinedTable, "undefined_table", "UndefinedTable"},
+	{UndefinedParameter, "undefined_parameter", "UndefinedParameter"},
+	{UndefinedObject, "undefined_object", "UndefinedObject"},
+	{DuplicateColumn, "duplicate_column", "DuplicateColumn"},
+	{DuplicateCursor, "duplicate_cursor", "DuplicateCursor"},
+	{DuplicateDatabase, "duplicate_database", "DuplicateDatabase"},
+	{DuplicateFunction, "duplicate_function", "DuplicateFunction"},
+	{DuplicatePreparedStatement, "duplicate_prepared_statement", "DuplicatePreparedStatement"},
+	{DuplicateSchema, "duplicate_schema", "DuplicateSchema"},
+	{DuplicateTable, "duplicate_table", "DuplicateTable"},
+	{DuplicateAlias, "duplicate_alias", "DuplicateAlias"},
+	{DuplicateObject, "duplicate_object", "DuplicateObject"},
+	{AmbiguousColumn, "ambiguous_column", "AmbiguousColumn"},
+	{AmbiguousFunction, "ambiguous_function", "AmbiguousFunction"},
+	{AmbiguousParameter, "ambiguous_parameter", "AmbiguousParameter"},
+	{AmbiguousAlias, "ambiguous_alias", "AmbiguousAlias"},
+	{InvalidColumnReference, "invalid_column_reference", "InvalidColumnReference"},
+	{InvalidColumnDefinition, "invalid_column_definition", "InvalidColumnDefinition"},
+	{InvalidCursorDefinition, "invalid_cursor_definition", "InvalidCursorDefinition"},
+	{InvalidDatabaseDefinition, "invalid_database_definition", "InvalidDatabaseDefinition"},
+	{InvalidFunctionDefinition, "invalid_function_definition", "InvalidFunctionDefinition"},
+	{InvalidPreparedStatementDefinition, "invalid_prepared_statement_definition", "InvalidPreparedStatementDefinition"},
+	{InvalidSchemaDefinition, "invalid_schema_definition", "InvalidSchemaDefinition"},
+	{InvalidTableDefinition, "invalid_table_definition", "InvalidTableDefinition"},
+	{InvalidObjectDefinition, "invalid_object_definition", "InvalidObjectDefinition"},
+
+	// Class 44 - WITH CHECK OPTION Violation
+	{WithCheckOptionViolation, "with_check_option_violation", "WithCheckOptionViolation"},
+
+	// Class 53 - Insufficient Resources
+	{InsufficientResources, "insufficient_resources", "InsufficientResources"},
+	{DiskFull, "disk_full", "DiskFull"},
+	{OutOfMemory, "out_of_memory", "OutOfMemory"},
+	{TooManyConnections, "too_many_connections", "TooManyConnections"},
+	{ConfigurationLimitExceeded, "configuration_limit_exceeded", "ConfigurationLimitExceeded"},
+
+	// Class 54 - Program Limit Exceeded
+	{ProgramLimitExceeded, "program_limit_exceeded", "ProgramLimitExceeded"},
+	{StatementTooComplex, "statement_too_complex", "StatementTooComplex"},
+	{TooManyColumns, "too_many_columns", "TooManyColumns"},
+	{TooManyArguments, "too_many_arguments", "TooManyArguments"},
+
+	// Class 55 - Object Not In Prerequisite State
+	{ObjectNotInPrerequisiteState, "object_not_in_prerequisite_state", "ObjectNotInPrerequisiteState"},
+	{ObjectInUse, "object_in_use", "ObjectInUse"},
+	{CantChangeRuntimeParam, "cant_change_runtime_param", "CantChangeRuntimeParam"},
+	{LockNotAvailable, "lock_not_available", "LockNotAvailable"},
+	{UnsafeNewEnumValueUsage, "unsafe_new_enum_value_usage", "UnsafeNewEnumValueUsage"},
+
+	// Class 57 - Operator Intervention
+	{OperatorIntervention, "operator_intervention", "OperatorIntervention"},
+	{QueryCanceled, "query_canceled", "QueryCanceled"},
+	{AdminShutdown, "admin_shutdown", "AdminShutdown"},
+	{CrashShutdown, "crash_shutdown", "CrashShutdown"},
+	{CannotConnectNow, "cannot_connect_now", "CannotConnectNow"},
+	{DatabaseDropped, "database_dropped", "DatabaseDropped"},
+
+	// Class 58 - System Error
+	{SystemError, "system_error", "SystemError"},
+	{IoError, "io_error", "IoError"},
+	{UndefinedFile, "undefined_file", "UndefinedFile"},
+	{DuplicateFile, "duplicate_file", "DuplicateFile"},
+
+	// Class 72 - Snapshot Failure
+	{SnapshotTooOld, "snapshot_too_old", "SnapshotTooOld"},
+
+	// Class F0 - Configuration File Error
+	{ConfigFileError, "config_file_error", "ConfigFileError"},
+	{LockFileExists, "lock_file_exists", "LockFileExists"},
+
+	// Class HV - Foreign Data Wrapper Error
+	{FdwError, "fdw_error", "FdwError"},
+	{FdwColumnNameNotFound, "fdw_column_name_not_found", "FdwColumnNameNotFound"},
+	{FdwDynamicParameterValueNeeded, "fdw_dynamic_parameter_value_needed", "FdwDynamicParameterValueNeeded"},
+	{FdwFunctionSequenceError, "fdw_function_sequence_error", "FdwFunctionSequenceError"},
+	{FdwInconsistentDescriptorInformation, "fdw_inconsistent_descriptor_information", "FdwInconsistentDescriptorInformation"},
+	{FdwInvalidAttributeValue, "fdw_invalid_attribute_value", "FdwInvalidAttributeValue"},
+	{FdwInvalidColumnName, "fdw_invalid_column_name", "FdwInvalidColumnName"},
+	{FdwInvalidColumnNumber, "fdw_invalid_column_number", "FdwInvalidColumnNumber"},
+	{FdwInvalidDataType, "fdw_invalid_data_type", "FdwInvalidDataType"},
+	{FdwInvalidDataTypeDescriptors, "fdw_invalid_data_type_descriptors", "FdwInvalidDataTypeDescriptors"},
+	{FdwInvalidDescriptorFieldIdentifier, "fdw_invalid_descriptor_field_identifier", "FdwInvalidDescriptorFieldIdentifier"},
+	{FdwInvalidHandle, "fdw_invalid_handle", "FdwInvalidHandle"},
+	{FdwInvalidOptionIndex, "fdw_invalid_option_index", "FdwInvalidOptionIndex"},
+	{FdwInvalidOptionName, "fdw_invalid_option_name", "FdwInvalidOptionName"},
+	{FdwInvalidStringLengthOrBufferLength, "fdw_invalid_string_length_or_buffer_length", "FdwInvalidStringLengthOrBufferLength"},
+	{FdwInvalidStringFormat, "fdw_invalid_string_format", "FdwInvalidStringFormat"},
+	{FdwInvalidUseOfNullPointer, "fdw_invalid_use_of_null_pointer", "FdwInvalidUseOfNullPointer"},
+	{FdwTooManyHandles, "fdw_too_many_handles", "FdwTooManyHandles"},
+	{FdwOutOfMemory, "fdw_out_of_memory", "FdwOutOfMemory"},
+	{FdwNoSchemas, "fdw_no_schemas", "FdwNoSchemas"},
+	{FdwOptionNameNotFound, "fdw_option_name_not_found", "FdwOptionNameNotFound"},
+	{FdwReplyHandle, "fdw_reply_handle", "FdwReplyHandle"},
+	{FdwSchemaNotFound, "fdw_schema_not_found", "FdwSchemaNotFound"},
+	{FdwTableNotFound, "fdw_table_not_found", "FdwTableNotFound"},
+	{FdwUnableToCreateExecution, "fdw_unable_to_create_execution", "FdwUnableToCreateExecution"},
+	{FdwUnableToCreateReply, "fdw_unable_to_create_reply", "FdwUnableToCreateReply"},
+	{FdwUnableToEstablishConnection, "fdw_unable_to_establish_connection", "FdwUnableToEstablishConnection"},
+
+	// Class P0 - PL/pgSQL Error
+	{PLpgSQLError, "plpgsql_error", "PLpgSQLError"},
+	{RaiseException, "raise_exception", "RaiseException"},
+	{NoDataFound, "no_data_found", "NoDataFound"},
+	{TooManyRows, "too_many_rows", "TooManyRows"},
+	{AssertFailure, "assert_failure", "AssertFailure"},
+
+	// Class XX - Internal Error
+	{InternalError, "internal_error", "InternalError"},
+	{DataCorrupted, "data_corrupted", "DataCorrupted"},
+	{IndexCorrupted, "index_corrupted", "IndexCorrupted"},
+}
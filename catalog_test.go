@@ -0,0 +1,43 @@
+package pqerror
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalogDescriptions(t *testing.T) {
+	for _, info := range Codes() {
+		if info.Description == "" {
+			t.Errorf("%s (%s) has no description", info.Code, info.Condition)
+			continue
+		}
+		// Descriptions explain the codes rather than spell out their names.
+		if strings.EqualFold(strings.TrimSuffix(info.Description, "."), strings.Replace(info.Condition, "_", " ", -1)) {
+			t.Errorf("%s description %q repeats the condition name", info.Code, info.Description)
+		}
+		if !strings.HasSuffix(info.Description, ".") {
+			t.Errorf("%s description %q is not a sentence", info.Code, info.Description)
+		}
+	}
+	for code := range codeDescriptions {
+		if _, ok := codeIndex[code]; !ok {
+			t.Errorf("description of %s, which is not in the catalog", code)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	info, ok := Lookup(UniqueViolation)
+	if !ok || info.Condition != "unique_violation" || info.Constant != "UniqueViolation" || info.Class() != ClassIntegrityConstraintViolation {
+		t.Errorf("Lookup(23505) = %+v, %v", info, ok)
+	}
+	if infos := LookupCondition("string_data_right_truncation"); len(infos) != 2 {
+		t.Errorf("LookupCondition(string_data_right_truncation) = %+v, want 2 entries", infos)
+	}
+	if info, ok := LookupConstant("DatetimeValueOutOfRange"); !ok || info.Code != DatetimeFieldOverflow {
+		t.Errorf("LookupConstant(DatetimeValueOutOfRange) = %+v, %v", info, ok)
+	}
+	if _, ok := Lookup("99999"); ok {
+		t.Errorf("Lookup(99999) found a code")
+	}
+}
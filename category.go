@@ -0,0 +1,223 @@
+package pqerror
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+// Category is a coarse grouping of errors by the way services usually react
+// to them.
+type Category int
+
+const (
+	// CategoryUnknown is the category of errors that cannot be categorized.
+	CategoryUnknown Category = iota
+	// CategoryInput is the category of errors caused by invalid input or
+	// a statement that is wrong, e.g. DivisionByZero, NotNullViolation or
+	// SyntaxError.
+	CategoryInput
+	// CategoryConflict is the category of errors caused by a conflict with
+	// the existing data or objects, e.g. UniqueViolation or
+	// ForeignKeyViolation.
+	CategoryConflict
+	// CategoryNotFound is the category of errors caused by a missing object,
+	// usually a sign of a schema drift, e.g. UndefinedTable or
+	// UndefinedColumn.
+	CategoryNotFound
+	// CategoryPermission is the category of errors caused by insufficient
+	// privileges, e.g. InsufficientPrivilege.
+	CategoryPermission
+	// CategoryTransient is the category of errors caused by concurrent
+	// activity, which may succeed when retried, e.g. SerializationFailure or
+	// DeadlockDetected.
+	CategoryTransient
+	// CategoryResource is the category of errors caused by an exhausted
+	// resource, e.g. DiskFull or OutOfMemory.
+	CategoryResource
+	// CategoryUnavailable is the category of errors caused by a lost or
+	// refused connection or a server going away, e.g. ConnectionFailure,
+	// TooManyConnections or AdminShutdown.
+	CategoryUnavailable
+	// CategoryInternal is the category of errors caused by a bug or data
+	// corruption, e.g. InternalError or DataCorrupted.
+	CategoryInternal
+	// CategoryInfo is the category of warnings and informational messages,
+	// e.g. WarningDeprecatedFeature.
+	CategoryInfo
+	// CategoryMisconfigured is the category of errors caused by the
+	// configuration of the client or the server, e.g. InvalidPassword,
+	// pq.ErrSSLNotSupported or an untrusted server certificate.
+	CategoryMisconfigured
+)
+
+var categoryNames = [...]string{
+	CategoryUnknown:       "unknown",
+	CategoryInput:         "input",
+	CategoryConflict:      "conflict",
+	CategoryNotFound:      "not_found",
+	CategoryPermission:    "permission",
+	CategoryTransient:     "transient",
+	CategoryResource:      "resource",
+	CategoryUnavailable:   "unavailable",
+	CategoryInternal:      "internal",
+	CategoryInfo:          "info",
+	CategoryMisconfigured: "misconfigured",
+}
+
+func (c Category) String() string {
+	if c >= 0 && int(c) < len(categoryNames) {
+		return categoryNames[c]
+	}
+	return "Category(" + strconv.Itoa(int(c)) + ")"
+}
+
+// CategoryOf returns the category of an error. The category hook is consulted
+// first, then the registered codes, see Register, and then the built-in
+// categories. If there is no *pq.Error in the chain of the error, the errors
+// of the driver and the network are categorized, see ConnFailureOf, e.g.
+// driver.ErrBadConn is CategoryUnavailable. Other errors are
+// CategoryUnknown.
+func CategoryOf(err error) Category {
+	pqerr, ok := asPqError(err)
+	if !ok {
+		if err == nil {
+			return CategoryUnknown
+		}
+		return driverCategory(err)
+	}
+	categoryHookMu.RLock()
+	hook := categoryHook
+	categoryHookMu.RUnlock()
+	if hook != nil {
+		if c, ok := hook(pqerr); ok {
+			return c
+		}
+	}
+	if c, ok := LookupCustom(pqerr.Code); ok {
+		return c.Category
+	}
+	return CodeCategory(pqerr.Code)
+}
+
+// CodeCategory returns the built-in category of a code. The codes of
+// connection failures are categorized consistently with ConnFailureOf, e.g.
+// InvalidPassword is CategoryMisconfigured and TooManyConnections is
+// CategoryUnavailable.
+func CodeCategory(code pq.ErrorCode) Category {
+	if f, ok := codeConnFailures[code]; ok {
+		return connFailureCategory(f)
+	}
+	if c, ok := codeCategories[code]; ok {
+		return c
+	}
+	if len(code) == 5 {
+		if c, ok := classCategories[code.Class()]; ok {
+			return c
+		}
+	}
+	return CategoryUnknown
+}
+
+var (
+	categoryHookMu sync.RWMutex
+	categoryHook   func(*pq.Error) (Category, bool)
+)
+
+// SetCategoryHook installs a function CategoryOf consults before the built-in
+// categories. The function reports false to leave the error to the built-in
+// categories. A nil function removes the hook.
+func SetCategoryHook(fn func(*pq.Error) (Category, bool)) {
+	categoryHookMu.Lock()
+	categoryHook = fn
+	categoryHookMu.Unlock()
+}
+
+var classCategories = map[pq.ErrorClass]Category{
+	ClassSuccessfulCompletion:                    CategoryInfo,
+	ClassWarning:                                 CategoryInfo,
+	ClassNoData:                                  CategoryInfo,
+	ClassSQLStatementNotYetComplete:              CategoryInput,
+	ClassConnectionException:                     CategoryUnavailable,
+	ClassTriggeredActionException:                CategoryInput,
+	ClassFeatureNotSupported:                     CategoryInput,
+	ClassInvalidTransactionInitiation:            CategoryInput,
+	ClassLocatorException:                        CategoryInput,
+	ClassInvalidGrantor:                          CategoryPermission,
+	ClassInvalidRoleSpecification:                CategoryPermission,
+	ClassDiagnosticsException:                    CategoryInput,
+	ClassCaseNotFound:                            CategoryInput,
+	ClassCardinalityViolation:                    CategoryInput,
+	ClassDataException:                           CategoryInput,
+	ClassIntegrityConstraintViolation:            CategoryConflict,
+	ClassInvalidCursorState:                      CategoryInput,
+	ClassInvalidTransactionState:                 CategoryInput,
+	ClassInvalidSQLStatementName:                 CategoryInput,
+	ClassTriggeredDataChangeViolation:            CategoryInput,
+	ClassInvalidAuthorizationSpecification:       CategoryPermission,
+	ClassDependentPrivilegeDescriptorsStillExist: CategoryConflict,
+	ClassInvalidTransactionTermination:           CategoryInput,
+	ClassSQLRoutineException:                     CategoryInput,
+	ClassInvalidCursorName:                       CategoryInput,
+	ClassExternalRoutineException:                CategoryInternal,
+	ClassExternalRoutineInvocationException:      CategoryInternal,
+	ClassSavepointException:                      CategoryInput,
+	ClassInvalidCatalogName:                      CategoryNotFound,
+	ClassInvalidSchemaName:                       CategoryNotFound,
+	ClassTransactionRollback:                     CategoryTransient,
+	ClassSyntaxErrorOrAccessRuleViolation:        CategoryInput,
+	ClassWithCheckOptionViolation:                CategoryInput,
+	ClassInsufficientResources:                   CategoryResource,
+	ClassProgramLimitExceeded:                    CategoryResource,
+	ClassObjectNotInPrerequisiteState:            CategoryConflict,
+	ClassOperatorIntervention:                    CategoryUnavailable,
+	ClassSystemError:                             CategoryInternal,
+	ClassSnapshotTooOld:                          CategoryTransient,
+	ClassConfigFileError:                         CategoryInternal,
+	ClassFdwError:                                CategoryInternal,
+	ClassPlpgsqlError:                            CategoryInput,
+	ClassInternalError:                           CategoryInternal,
+}
+
+// codeCategories lists the codes whose category differs from the category of
+// their class.
+var codeCategories = map[pq.ErrorCode]Category{
+	NotNullViolation: CategoryInput,
+	CheckViolation:   CategoryInput,
+
+	ReadOnlySQLTransaction:          CategoryUnavailable,
+	IdleInTransactionSessionTimeout: CategoryUnavailable,
+
+	TransactionIntegrityConstraintViolation: CategoryConflict,
+	// The outcome of the statement is unknown, as with
+	// TransactionResolutionUnknown, so retrying it is not safe.
+	StatementCompletionUnknown: CategoryUnavailable,
+
+	InsufficientPrivilege:      CategoryPermission,
+	UndefinedColumn:            CategoryNotFound,
+	UndefinedFunction:          CategoryNotFound,
+	UndefinedTable:             CategoryNotFound,
+	UndefinedParameter:         CategoryNotFound,
+	UndefinedObject:            CategoryNotFound,
+	DuplicateColumn:            CategoryConflict,
+	DuplicateCursor:            CategoryConflict,
+	DuplicateDatabase:          CategoryConflict,
+	DuplicateFunction:          CategoryConflict,
+	DuplicatePreparedStatement: CategoryConflict,
+	DuplicateSchema:            CategoryConflict,
+	DuplicateTable:             CategoryConflict,
+	DuplicateAlias:             CategoryConflict,
+	DuplicateObject:            CategoryConflict,
+
+	ObjectInUse:            CategoryTransient,
+	CantChangeRuntimeParam: CategoryInput,
+	LockNotAvailable:       CategoryTransient,
+
+	QueryCanceled: CategoryTransient,
+
+	UndefinedFile: CategoryNotFound,
+
+	NoDataFound:   CategoryNotFound,
+	AssertFailure: CategoryInternal,
+}
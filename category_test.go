@@ -0,0 +1,85 @@
+package pqerror
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestCategoryOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want Category
+	}{
+		{nil, CategoryUnknown},
+		{errors.New("boom"), CategoryUnknown},
+		{context.Canceled, CategoryUnknown},
+		{&pq.Error{Code: UniqueViolation}, CategoryConflict},
+		{&pq.Error{Code: NotNullViolation}, CategoryInput},
+		{&pq.Error{Code: UndefinedTable}, CategoryNotFound},
+		{&pq.Error{Code: InsufficientPrivilege}, CategoryPermission},
+		{&pq.Error{Code: SerializationFailure}, CategoryTransient},
+		{&pq.Error{Code: StatementCompletionUnknown}, CategoryUnavailable},
+		{&pq.Error{Code: TransactionResolutionUnknown}, CategoryUnavailable},
+		{&pq.Error{Code: DiskFull}, CategoryResource},
+		{&pq.Error{Code: InternalError}, CategoryInternal},
+		{&pq.Error{Code: WarningDeprecatedFeature}, CategoryInfo},
+
+		{&pq.Error{Code: InvalidPassword}, CategoryMisconfigured},
+		{&pq.Error{Code: InvalidAuthorizationSpecification}, CategoryMisconfigured},
+		{&pq.Error{Code: TooManyConnections}, CategoryUnavailable},
+		{&pq.Error{Code: CannotConnectNow}, CategoryUnavailable},
+		{&pq.Error{Code: AdminShutdown}, CategoryUnavailable},
+		{&pq.Error{Code: ProtocolViolation}, CategoryUnavailable},
+		{fmt.Errorf("connect: %w", &pq.Error{Code: InvalidPassword}), CategoryMisconfigured},
+
+		{driver.ErrBadConn, CategoryUnavailable},
+		{io.ErrUnexpectedEOF, CategoryUnavailable},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, CategoryUnavailable},
+		{pq.ErrSSLNotSupported, CategoryMisconfigured},
+		{pq.ErrInFailedTransaction, CategoryInput},
+	}
+	for _, tt := range tests {
+		if got := CategoryOf(tt.err); got != tt.want {
+			t.Errorf("CategoryOf(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestCategoryOfConnFailures checks that CategoryOf agrees with ConnFailureOf
+// on the codes of connection failures.
+func TestCategoryOfConnFailures(t *testing.T) {
+	for code, f := range codeConnFailures {
+		err := &pq.Error{Code: code}
+		if got := ConnFailureOf(err); got != f {
+			t.Errorf("ConnFailureOf(%s) = %v, want %v", code, got, f)
+		}
+		want := CategoryUnavailable
+		if f == ConnMisconfigured {
+			want = CategoryMisconfigured
+		}
+		if got := CategoryOf(err); got != want {
+			t.Errorf("CategoryOf(%s) = %v, want %v as it is %v", code, got, want, f)
+		}
+	}
+}
+
+func TestCategoryHook(t *testing.T) {
+	defer SetCategoryHook(nil)
+	SetCategoryHook(func(err *pq.Error) (Category, bool) {
+		return CategoryPermission, err.Code == InvalidPassword
+	})
+	if got := CategoryOf(&pq.Error{Code: InvalidPassword}); got != CategoryPermission {
+		t.Errorf("CategoryOf(InvalidPassword) with hook = %v, want %v", got, CategoryPermission)
+	}
+	if got := CategoryOf(&pq.Error{Code: TooManyConnections}); got != CategoryUnavailable {
+		t.Errorf("CategoryOf(TooManyConnections) with hook = %v, want %v", got, CategoryUnavailable)
+	}
+}
@@ -0,0 +1,483 @@
+// Command pqerror looks up PostgreSQL error codes.
+//
+// Usage:
+//
+//	pqerror [-json] CODE|CONDITION|CONSTANT...
+//	pqerror [-json] class CLASS...
+//	pqerror [-json] parse [FILE...]
+//	pqerror [-json] logstats [-format FORMAT] [-prefix PREFIX] [-bucket DURATION] [FILE...]
+//
+// A code is looked up by its SQLSTATE (23505), its condition name
+// (unique_violation) or the name of its Go constant (UniqueViolation).
+//
+// The parse command reads error reports as printed by psql or written to a
+// log from the files, or from the standard input if none are given, and
+// prints their fields.
+//
+// The logstats command reads PostgreSQL server logs from the files, or from
+// the standard input if none are given, and counts the errors and warnings
+// by code, class, database, user and time bucket. The format is one of
+// stderr, csvlog and jsonlog. The stderr format requires the log_line_prefix
+// of the server, which must contain %e.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/michaljemala/pqerror"
+)
+
+const usage = `usage:
+  pqerror [-json] CODE|CONDITION|CONSTANT...
+  pqerror [-json] class CLASS...
+  pqerror [-json] parse [FILE...]
+  pqerror [-json] logstats [-format FORMAT] [-prefix PREFIX] [-bucket DURATION] [FILE...]
+`
+
+// errNotFound is reported when an argument does not resolve to a known code
+// or class. It makes the command exit with status 1 rather than 2.
+var errNotFound = errors.New("not found")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("pqerror", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	asJSON := fs.Bool("json", false, "print machine-readable output")
+	logFormat := fs.String("format", "stderr", "server log `format`: stderr, csvlog or jsonlog")
+	logPrefix := fs.String("prefix", "", "log_line_`prefix` of a stderr log")
+	logBucket := fs.Duration("bucket", time.Hour, "`duration` of the time buckets")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(args) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var (
+		out interface{}
+		n   int
+	)
+	switch args[0] {
+	case "class":
+		if len(args) < 2 {
+			fs.Usage()
+			return 2
+		}
+		classes, e := lookupClasses(args[1:])
+		out, n, err = classes, len(classes), e
+	case "parse":
+		reports, e := parseReports(args[1:], stdin)
+		out, n, err = reports, len(reports), e
+	case "logstats":
+		stats, e := analyzeLogs(args[1:], stdin, *logFormat, *logPrefix, *logBucket)
+		out, err = stats, e
+		if stats != nil {
+			n = 1
+		}
+	default:
+		codes, e := lookupCodes(args)
+		out, n, err = codes, len(codes), e
+	}
+	if n > 0 {
+		if *asJSON {
+			err2 := writeJSON(stdout, out)
+			if err == nil {
+				err = err2
+			}
+		} else {
+			writeText(stdout, out)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		if errors.Is(err, errNotFound) {
+			return 1
+		}
+		return 2
+	}
+	return 0
+}
+
+// parseArgs parses flags interspersed with positional arguments, so that both
+// "pqerror -json 23505" and "pqerror 23505 -json" work.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return rest, nil
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+}
+
+type codeOutput struct {
+	Code        pq.ErrorCode  `json:"code"`
+	Condition   string        `json:"condition"`
+	Constant    string        `json:"constant"`
+	Class       pq.ErrorClass `json:"class"`
+	ClassTitle  string        `json:"class_title"`
+	Description string        `json:"description"`
+}
+
+type classOutput struct {
+	Class    pq.ErrorClass `json:"class"`
+	Title    string        `json:"title"`
+	Constant string        `json:"constant"`
+	Codes    []codeOutput  `json:"codes"`
+}
+
+func newCodeOutput(info pqerror.CodeInfo) codeOutput {
+	class, _ := pqerror.LookupClass(info.Class())
+	return codeOutput{
+		Code:        info.Code,
+		Condition:   info.Condition,
+		Constant:    info.Constant,
+		Class:       info.Class(),
+		ClassTitle:  class.Title,
+		Description: info.Description,
+	}
+}
+
+func lookupCodes(args []string) ([]codeOutput, error) {
+	var (
+		out     []codeOutput
+		missing []string
+	)
+	for _, arg := range args {
+		infos := resolveCode(arg)
+		if len(infos) == 0 {
+			missing = append(missing, arg)
+			continue
+		}
+		for _, info := range infos {
+			out = append(out, newCodeOutput(info))
+		}
+	}
+	if len(missing) > 0 {
+		return out, fmt.Errorf("pqerror: unknown code %s: %w", strings.Join(missing, ", "), errNotFound)
+	}
+	return out, nil
+}
+
+// resolveCode resolves an argument given as a SQLSTATE, a condition name or
+// a Go constant name.
+func resolveCode(arg string) []pqerror.CodeInfo {
+	if info, ok := pqerror.Lookup(pq.ErrorCode(strings.ToUpper(arg))); ok {
+		return []pqerror.CodeInfo{info}
+	}
+	if infos := pqerror.LookupCondition(arg); len(infos) > 0 {
+		return infos
+	}
+	if info, ok := pqerror.LookupConstant(arg); ok {
+		return []pqerror.CodeInfo{info}
+	}
+	return nil
+}
+
+func lookupClasses(args []string) ([]classOutput, error) {
+	var (
+		out     []classOutput
+		missing []string
+	)
+	for _, arg := range args {
+		info, ok := resolveClass(arg)
+		if !ok {
+			missing = append(missing, arg)
+			continue
+		}
+		class := classOutput{
+			Class:    info.Class,
+			Title:    info.Title,
+			Constant: info.Constant,
+		}
+		for _, code := range pqerror.ClassCodes(info.Class) {
+			class.Codes = append(class.Codes, newCodeOutput(code))
+		}
+		out = append(out, class)
+	}
+	if len(missing) > 0 {
+		return out, fmt.Errorf("pqerror: unknown class %s: %w", strings.Join(missing, ", "), errNotFound)
+	}
+	return out, nil
+}
+
+// resolveClass resolves an argument given as a two-character class or as
+// a Go constant name.
+func resolveClass(arg string) (pqerror.ClassInfo, bool) {
+	if info, ok := pqerror.LookupClass(pq.ErrorClass(strings.ToUpper(arg))); ok {
+		return info, true
+	}
+	for _, info := range pqerror.Classes() {
+		if info.Constant == arg {
+			return info, true
+		}
+	}
+	return pqerror.ClassInfo{}, false
+}
+
+type reportOutput struct {
+	Severity         string        `json:"severity,omitempty"`
+	Code             pq.ErrorCode  `json:"code,omitempty"`
+	Condition        string        `json:"condition,omitempty"`
+	Class            pq.ErrorClass `json:"class,omitempty"`
+	Message          string        `json:"message"`
+	Detail           string        `json:"detail,omitempty"`
+	Hint             string        `json:"hint,omitempty"`
+	Position         string        `json:"position,omitempty"`
+	InternalPosition string        `json:"internal_position,omitempty"`
+	InternalQuery    string        `json:"internal_query,omitempty"`
+	Where            string        `json:"where,omitempty"`
+	Schema           string        `json:"schema,omitempty"`
+	Table            string        `json:"table,omitempty"`
+	Column           string        `json:"column,omitempty"`
+	DataTypeName     string        `json:"data_type,omitempty"`
+	Constraint       string        `json:"constraint,omitempty"`
+	File             string        `json:"file,omitempty"`
+	Line             string        `json:"line,omitempty"`
+	Routine          string        `json:"routine,omitempty"`
+}
+
+func newReportOutput(err *pq.Error) reportOutput {
+	out := reportOutput{
+		Severity:         err.Severity,
+		Code:             err.Code,
+		Message:          err.Message,
+		Detail:           err.Detail,
+		Hint:             err.Hint,
+		Position:         err.Position,
+		InternalPosition: err.InternalPosition,
+		InternalQuery:    err.InternalQuery,
+		Where:            err.Where,
+		Schema:           err.Schema,
+		Table:            err.Table,
+		Column:           err.Column,
+		DataTypeName:     err.DataTypeName,
+		Constraint:       err.Constraint,
+		File:             err.File,
+		Line:             err.Line,
+		Routine:          err.Routine,
+	}
+	if info, ok := pqerror.Lookup(err.Code); ok {
+		out.Condition = info.Condition
+		out.Class = info.Class()
+	}
+	return out
+}
+
+func parseReports(files []string, stdin io.Reader) ([]reportOutput, error) {
+	var text []byte
+	if len(files) == 0 {
+		b, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("pqerror: %w", err)
+		}
+		text = b
+	}
+	for _, name := range files {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("pqerror: %w", err)
+		}
+		text = append(append(text, b...), '\n')
+	}
+	errs, err := pqerror.ParseTextAll(string(text))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]reportOutput, len(errs))
+	for i, e := range errs {
+		out[i] = newReportOutput(e)
+	}
+	return out, nil
+}
+
+type countOutput struct {
+	Key   string `json:"key"`
+	Name  string `json:"name,omitempty"`
+	Count int    `json:"count"`
+}
+
+type logStatsOutput struct {
+	Total      int           `json:"total"`
+	ByCode     []countOutput `json:"by_code"`
+	ByClass    []countOutput `json:"by_class"`
+	ByDatabase []countOutput `json:"by_database"`
+	ByUser     []countOutput `json:"by_user"`
+	ByTime     []countOutput `json:"by_time"`
+}
+
+func analyzeLogs(files []string, stdin io.Reader, format, prefix string, bucket time.Duration) (*logStatsOutput, error) {
+	f, err := pqerror.ParseLogFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	stats := pqerror.NewLogStats(bucket)
+	analyze := func(r io.Reader) error {
+		lr, err := pqerror.NewLogReader(r, f, prefix)
+		if err != nil {
+			return err
+		}
+		return stats.AddAll(lr)
+	}
+	if len(files) == 0 {
+		if err := analyze(stdin); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("pqerror: %w", err)
+		}
+		err = analyze(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w (reading %s)", err, name)
+		}
+	}
+
+	out := &logStatsOutput{Total: stats.Total}
+	for code, n := range stats.ByCode {
+		c := countOutput{Key: string(code), Count: n}
+		if info, ok := pqerror.Lookup(code); ok {
+			c.Name = info.Condition
+		}
+		out.ByCode = append(out.ByCode, c)
+	}
+	for class, n := range stats.ByClass {
+		c := countOutput{Key: string(class), Count: n}
+		if info, ok := pqerror.LookupClass(class); ok {
+			c.Name = info.Title
+		}
+		out.ByClass = append(out.ByClass, c)
+	}
+	for db, n := range stats.ByDatabase {
+		out.ByDatabase = append(out.ByDatabase, countOutput{Key: db, Count: n})
+	}
+	for user, n := range stats.ByUser {
+		out.ByUser = append(out.ByUser, countOutput{Key: user, Count: n})
+	}
+	for t, n := range stats.ByTime {
+		out.ByTime = append(out.ByTime, countOutput{Key: t.Format(time.RFC3339), Count: n})
+	}
+	sortByCount(out.ByCode)
+	sortByCount(out.ByClass)
+	sortByCount(out.ByDatabase)
+	sortByCount(out.ByUser)
+	sort.Slice(out.ByTime, func(i, j int) bool { return out.ByTime[i].Key < out.ByTime[j].Key })
+	return out, nil
+}
+
+func sortByCount(counts []countOutput) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Key < counts[j].Key
+	})
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeText(w io.Writer, v interface{}) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+	switch v := v.(type) {
+	case []codeOutput:
+		for i, c := range v {
+			if i > 0 {
+				fmt.Fprintln(tw)
+			}
+			fmt.Fprintf(tw, "Code:\t%s\n", c.Code)
+			fmt.Fprintf(tw, "Condition:\t%s\n", c.Condition)
+			fmt.Fprintf(tw, "Class:\t%s - %s\n", c.Class, c.ClassTitle)
+			fmt.Fprintf(tw, "Constant:\tpqerror.%s\n", c.Constant)
+			fmt.Fprintf(tw, "Description:\t%s\n", c.Description)
+		}
+	case []classOutput:
+		for i, c := range v {
+			if i > 0 {
+				fmt.Fprintln(tw)
+			}
+			fmt.Fprintf(tw, "Class %s - %s (pqerror.%s)\n", c.Class, c.Title, c.Constant)
+			for _, code := range c.Codes {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", code.Code, code.Condition, code.Constant)
+			}
+		}
+	case []reportOutput:
+		for i, r := range v {
+			if i > 0 {
+				fmt.Fprintln(tw)
+			}
+			field := func(name, value string) {
+				if value != "" {
+					fmt.Fprintf(tw, "%s:\t%s\n", name, strings.Replace(value, "\n", "\n\t", -1))
+				}
+			}
+			field("Severity", r.Severity)
+			field("Code", string(r.Code))
+			field("Condition", r.Condition)
+			field("Message", r.Message)
+			field("Detail", r.Detail)
+			field("Hint", r.Hint)
+			field("Position", r.Position)
+			field("Internal query", r.InternalQuery)
+			field("Internal position", r.InternalPosition)
+			field("Context", r.Where)
+			field("Schema", r.Schema)
+			field("Table", r.Table)
+			field("Column", r.Column)
+			field("Data type", r.DataTypeName)
+			field("Constraint", r.Constraint)
+			field("Routine", r.Routine)
+			field("File", r.File)
+			field("Line", r.Line)
+		}
+	case *logStatsOutput:
+		fmt.Fprintf(tw, "Total:\t%d\n", v.Total)
+		section := func(title string, counts []countOutput) {
+			if len(counts) == 0 {
+				return
+			}
+			fmt.Fprintf(tw, "\n%s:\n", title)
+			for _, c := range counts {
+				key := c.Key
+				if key == "" {
+					key = "-"
+				}
+				fmt.Fprintf(tw, "  %s\t%s\t%d\n", key, c.Name, c.Count)
+			}
+		}
+		section("By code", v.ByCode)
+		section("By class", v.ByClass)
+		section("By database", v.ByDatabase)
+		section("By user", v.ByUser)
+		section("By time", v.ByTime)
+	}
+}
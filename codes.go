@@ -2,7 +2,9 @@
 // helpers to inspect PostgreSQL errors.
 package pqerror
 
-import "github.com/lib/pq"
+import (
+	"github.com/lib/pq"
+)
 
 // See https://www.postgresql.org/docs/11/static/errcodes-appendix.html
 // and https://github.com/postgres/postgres/blob/REL_12_STABLE/src/backend/utils/errcodes.txt.
@@ -20,6 +22,56 @@ func IsCode(err error, code pq.ErrorCode) bool {
 	return ok && pqerr.Code == code
 }
 
+// Catches reports whether an exception block of PL/pgSQL catching any of the
+// given codes would catch an error, i.e. whether there is a *pq.Error in the
+// chain of the error whose code is one of the codes, or whose class is the
+// class of one of the generic codes, e.g. IntegrityConstraintViolation (23000)
+// catches UniqueViolation (23505). See IsGeneric.
+func Catches(err error, codes ...pq.ErrorCode) bool {
+	pqerr, ok := asPqError(err)
+	return ok && catches(pqerr.Code, codes)
+}
+
+func catches(code pq.ErrorCode, codes []pq.ErrorCode) bool {
+	for _, c := range codes {
+		if code == c || IsGeneric(c) && len(code) == 5 && code.Class() == c.Class() {
+			return true
+		}
+	}
+	return false
+}
+
+// asPqError returns the first *pq.Error in the chain of an error. It walks
+// the chain the way errors.As does, but does not allocate unless an error of
+// the chain has an As method.
+func asPqError(err error) (*pq.Error, bool) {
+	for err != nil {
+		if pqerr, ok := err.(*pq.Error); ok {
+			return pqerr, true
+		}
+		if x, ok := err.(interface{ As(interface{}) bool }); ok {
+			var pqerr *pq.Error
+			if x.As(&pqerr) {
+				return pqerr, true
+			}
+		}
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				if pqerr, ok := asPqError(err); ok {
+					return pqerr, true
+				}
+			}
+			return nil, false
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
 const (
 	ClassSuccessfulCompletion                    = pq.ErrorClass("00")
 	ClassWarning                                 = pq.ErrorClass("01")
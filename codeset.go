@@ -0,0 +1,137 @@
+package pqerror
+
+import (
+	"sort"
+
+	"github.com/lib/pq"
+)
+
+// CodeSet is an immutable set of codes. Its lookups do not allocate, which
+// makes it suitable for matching every error of a hot path, e.g.
+//
+//	var retryable = pqerror.NewCodeSet(
+//		pqerror.SerializationFailure,
+//		pqerror.DeadlockDetected,
+//		pqerror.LockNotAvailable,
+//	)
+//	...
+//	if retryable.Match(err) {
+//		...
+//	}
+//
+// The zero CodeSet is empty.
+type CodeSet struct {
+	packed []uint32 // sorted, see Pack
+}
+
+// NewCodeSet returns a set of given codes. It panics if any of the codes is
+// not valid, see ValidCode.
+func NewCodeSet(codes ...pq.ErrorCode) CodeSet {
+	packed := make([]uint32, 0, len(codes))
+	for _, code := range codes {
+		n := Pack(code)
+		if n == InvalidPacked {
+			panic("pqerror: invalid code " + string(code))
+		}
+		packed = append(packed, n)
+	}
+	return newCodeSet(packed)
+}
+
+func newCodeSet(packed []uint32) CodeSet {
+	sort.Slice(packed, func(i, j int) bool { return packed[i] < packed[j] })
+	out := packed[:0]
+	for i, n := range packed {
+		if i == 0 || n != packed[i-1] {
+			out = append(out, n)
+		}
+	}
+	return CodeSet{packed: out}
+}
+
+// Len returns the number of codes of the set.
+func (s CodeSet) Len() int {
+	return len(s.packed)
+}
+
+// Contains reports whether a code is in the set.
+func (s CodeSet) Contains(code pq.ErrorCode) bool {
+	n := Pack(code)
+	if n == InvalidPacked {
+		return false
+	}
+	i := sort.Search(len(s.packed), func(i int) bool { return s.packed[i] >= n })
+	return i < len(s.packed) && s.packed[i] == n
+}
+
+// ContainsClass reports whether any code of a class is in the set.
+func (s CodeSet) ContainsClass(class pq.ErrorClass) bool {
+	if len(class) != 2 {
+		return false
+	}
+	n := Pack(pq.ErrorCode(class) + "000")
+	if n == InvalidPacked {
+		return false
+	}
+	// The class takes the lowest 12 bits.
+	for _, m := range s.packed {
+		if m&0xFFF == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether there is a *pq.Error in the chain of an error whose
+// code is in the set.
+func (s CodeSet) Match(err error) bool {
+	pqerr, ok := asPqError(err)
+	return ok && s.Contains(pqerr.Code)
+}
+
+// Union returns the set of the codes in either set.
+func (s CodeSet) Union(other CodeSet) CodeSet {
+	packed := make([]uint32, 0, len(s.packed)+len(other.packed))
+	packed = append(packed, s.packed...)
+	packed = append(packed, other.packed...)
+	return newCodeSet(packed)
+}
+
+// Intersect returns the set of the codes in both sets.
+func (s CodeSet) Intersect(other CodeSet) CodeSet {
+	var packed []uint32
+	i, j := 0, 0
+	for i < len(s.packed) && j < len(other.packed) {
+		switch a, b := s.packed[i], other.packed[j]; {
+		case a < b:
+			i++
+		case a > b:
+			j++
+		default:
+			packed = append(packed, a)
+			i++
+			j++
+		}
+	}
+	return CodeSet{packed: packed}
+}
+
+// Codes returns the codes of the set in ascending order.
+func (s CodeSet) Codes() []pq.ErrorCode {
+	codes := make([]pq.ErrorCode, len(s.packed))
+	for i, n := range s.packed {
+		codes[i] = Unpack(n)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+// Each calls a function for each code of the set, in no particular order,
+// until the function returns false.
+func (s CodeSet) Each(fn func(code pq.ErrorCode) bool) {
+	for _, n := range s.packed {
+		if !fn(Unpack(n)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package pqerror
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+var retryableCodes = NewCodeSet(SerializationFailure, DeadlockDetected, LockNotAvailable, QueryCanceled)
+
+// asError wraps an error in an error implementing As, as some error types of
+// other packages do.
+type asError struct{ err *pq.Error }
+
+func (e asError) Error() string { return e.err.Error() }
+
+func (e asError) As(target interface{}) bool {
+	if p, ok := target.(**pq.Error); ok {
+		*p = e.err
+		return true
+	}
+	return false
+}
+
+// multiError wraps several errors, as errors.Join does.
+type multiError []error
+
+func (e multiError) Error() string { return fmt.Sprint([]error(e)) }
+
+func (e multiError) Unwrap() []error { return e }
+
+func TestCodeSet(t *testing.T) {
+	s := NewCodeSet(DeadlockDetected, SerializationFailure, DeadlockDetected)
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", s.Len())
+	}
+	if want := []pq.ErrorCode{SerializationFailure, DeadlockDetected}; !reflect.DeepEqual(s.Codes(), want) {
+		t.Errorf("Codes() = %v, want %v", s.Codes(), want)
+	}
+	for code, want := range map[pq.ErrorCode]bool{
+		SerializationFailure: true,
+		DeadlockDetected:     true,
+		UniqueViolation:      false,
+		"":                   false,
+		"4000":               false,
+		"4o001":              false,
+	} {
+		if got := s.Contains(code); got != want {
+			t.Errorf("Contains(%q) = %v, want %v", code, got, want)
+		}
+	}
+	if !s.ContainsClass(ClassTransactionRollback) || s.ContainsClass(ClassIntegrityConstraintViolation) {
+		t.Errorf("ContainsClass() mismatch")
+	}
+
+	other := NewCodeSet(DeadlockDetected, UniqueViolation)
+	if want := []pq.ErrorCode{UniqueViolation, SerializationFailure, DeadlockDetected}; !reflect.DeepEqual(s.Union(other).Codes(), want) {
+		t.Errorf("Union() = %v, want %v", s.Union(other).Codes(), want)
+	}
+	if want := []pq.ErrorCode{DeadlockDetected}; !reflect.DeepEqual(s.Intersect(other).Codes(), want) {
+		t.Errorf("Intersect() = %v, want %v", s.Intersect(other).Codes(), want)
+	}
+	if (CodeSet{}).Contains(DeadlockDetected) || (CodeSet{}).Len() != 0 {
+		t.Errorf("zero CodeSet is not empty")
+	}
+}
+
+func TestCodeSetMatch(t *testing.T) {
+	deadlock := &pq.Error{Code: DeadlockDetected}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", deadlock, true},
+		{"wrapped", fmt.Errorf("transfer: %w", fmt.Errorf("update: %w", deadlock)), true},
+		{"as", fmt.Errorf("transfer: %w", asError{deadlock}), true},
+		{"multi", multiError{errors.New("rollback failed"), fmt.Errorf("update: %w", deadlock)}, true},
+		{"multi without pq", multiError{errors.New("rollback failed")}, false},
+		{"other code", &pq.Error{Code: UniqueViolation}, false},
+		{"not pq", errors.New("deadlock detected"), false},
+		{"opaque", fmt.Errorf("transfer: %v", deadlock), false},
+	}
+	for _, tt := range tests {
+		if got := retryableCodes.Match(tt.err); got != tt.want {
+			t.Errorf("%s: Match(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCodeSetAllocs(t *testing.T) {
+	unique := &pq.Error{Code: UniqueViolation}
+	wrapped := fmt.Errorf("transfer: %w", fmt.Errorf("update: %w", &pq.Error{Code: DeadlockDetected}))
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"Contains", func() { retryableCodes.Contains(DeadlockDetected) }},
+		{"Match", func() { retryableCodes.Match(unique) }},
+		{"Match wrapped", func() { retryableCodes.Match(wrapped) }},
+		{"Catches wrapped", func() { Catches(wrapped, DeadlockDetected) }},
+	}
+	for _, tt := range tests {
+		if n := testing.AllocsPerRun(100, tt.fn); n != 0 {
+			t.Errorf("%s allocates %v times, want 0", tt.name, n)
+		}
+	}
+}
+
+func BenchmarkCodeSetContains(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		retryableCodes.Contains(DeadlockDetected)
+	}
+}
+
+func BenchmarkCodeSetMatch(b *testing.B) {
+	err := fmt.Errorf("transfer: %w", &pq.Error{Code: DeadlockDetected})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		retryableCodes.Match(err)
+	}
+}
+
+func BenchmarkCatches(b *testing.B) {
+	err := fmt.Errorf("transfer: %w", &pq.Error{Code: DeadlockDetected})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Catches(err, SerializationFailure, DeadlockDetected, LockNotAvailable, QueryCanceled)
+	}
+}
+
+// The benchmarks below measure the alternatives to a CodeSet: a chain of
+// IsCode calls, which sees an unwrapped error only, and a map built for every
+// request.
+
+func BenchmarkIsCodeChain(b *testing.B) {
+	var err error = &pq.Error{Code: QueryCanceled}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = IsCode(err, SerializationFailure) ||
+			IsCode(err, DeadlockDetected) ||
+			IsCode(err, LockNotAvailable) ||
+			IsCode(err, QueryCanceled)
+	}
+}
+
+func BenchmarkCodeMap(b *testing.B) {
+	err := fmt.Errorf("transfer: %w", &pq.Error{Code: DeadlockDetected})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		codes := map[pq.ErrorCode]bool{
+			SerializationFailure: true,
+			DeadlockDetected:     true,
+			LockNotAvailable:     true,
+			QueryCanceled:        true,
+		}
+		var pqerr *pq.Error
+		_ = errors.As(err, &pqerr) && codes[pqerr.Code]
+	}
+}
@@ -0,0 +1,155 @@
+package pqerror
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"syscall"
+
+	"github.com/lib/pq"
+)
+
+// ConnFailure is the kind of a connection failure. Connection failures are
+// reported both by the server, e.g. AdminShutdown, and by the driver or the
+// network, e.g. driver.ErrBadConn, io.EOF or *net.OpError.
+type ConnFailure int
+
+const (
+	// ConnFailureNone is the kind of errors that are not connection failures.
+	ConnFailureNone ConnFailure = iota
+	// ConnLost is the kind of failures of established connections, e.g.
+	// AdminShutdown, ConnectionFailure or io.EOF.
+	ConnLost
+	// ConnCannotConnect is the kind of failures to establish a connection,
+	// e.g. CannotConnectNow, TooManyConnections or a refused dial.
+	ConnCannotConnect
+	// ConnMisconfigured is the kind of failures caused by the configuration
+	// of the client or the server, e.g. InvalidPassword, pq.ErrSSLNotSupported
+	// or an untrusted certificate.
+	ConnMisconfigured
+)
+
+var connFailureNames = [...]string{
+	ConnFailureNone:   "none",
+	ConnLost:          "lost",
+	ConnCannotConnect: "cannot_connect",
+	ConnMisconfigured: "misconfigured",
+}
+
+func (f ConnFailure) String() string {
+	if f >= 0 && int(f) < len(connFailureNames) {
+		return connFailureNames[f]
+	}
+	return "ConnFailure(" + strconv.Itoa(int(f)) + ")"
+}
+
+// ConnFailureOf returns the kind of connection failure of an error, looking
+// at the *pq.Error in the chain of the error, if any, and at the errors of
+// the driver and the network otherwise.
+func ConnFailureOf(err error) ConnFailure {
+	if err == nil {
+		return ConnFailureNone
+	}
+	if pqerr, ok := asPqError(err); ok {
+		if f, ok := codeConnFailures[pqerr.Code]; ok {
+			return f
+		}
+		return ConnFailureNone
+	}
+	return driverConnFailure(err)
+}
+
+var codeConnFailures = map[pq.ErrorCode]ConnFailure{
+	ConnectionException:                           ConnLost,
+	ConnectionDoesNotExist:                        ConnLost,
+	ConnectionFailure:                             ConnLost,
+	SQLClientUnableToEstablishSQLConnection:       ConnCannotConnect,
+	SQLServerRejectedEstablishmentOfSQLConnection: ConnCannotConnect,
+	TransactionResolutionUnknown:                  ConnLost,
+	ProtocolViolation:                             ConnLost,
+
+	InvalidAuthorizationSpecification: ConnMisconfigured,
+	InvalidPassword:                   ConnMisconfigured,
+
+	TooManyConnections: ConnCannotConnect,
+
+	AdminShutdown:    ConnLost,
+	CrashShutdown:    ConnLost,
+	CannotConnectNow: ConnCannotConnect,
+	DatabaseDropped:  ConnLost,
+}
+
+// driverConnFailure returns the kind of connection failure of an error that
+// is not a PostgreSQL error.
+func driverConnFailure(err error) ConnFailure {
+	switch {
+	case errors.Is(err, pq.ErrSSLNotSupported),
+		errors.Is(err, pq.ErrSSLKeyHasWorldPermissions),
+		errors.Is(err, pq.ErrCouldNotDetectUsername):
+		return ConnMisconfigured
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE):
+		return ConnLost
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ConnCannotConnect
+	}
+
+	var (
+		recordErr    tls.RecordHeaderError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		certErr      x509.CertificateInvalidError
+	)
+	if errors.As(err, &recordErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &certErr) {
+		return ConnMisconfigured
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ConnCannotConnect
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		if opErr.Op == "dial" {
+			return ConnCannotConnect
+		}
+		return ConnLost
+	}
+	return ConnFailureNone
+}
+
+// driverCategory returns the category of an error that is not a PostgreSQL
+// error.
+func driverCategory(err error) Category {
+	if c := connFailureCategory(driverConnFailure(err)); c != CategoryUnknown {
+		return c
+	}
+	switch {
+	case errors.Is(err, pq.ErrChannelAlreadyOpen),
+		errors.Is(err, pq.ErrChannelNotOpen),
+		errors.Is(err, pq.ErrInFailedTransaction),
+		errors.Is(err, pq.ErrNotSupported):
+		return CategoryInput
+	}
+	return CategoryUnknown
+}
+
+// connFailureCategory returns the category of a kind of connection failure,
+// or CategoryUnknown for ConnFailureNone.
+func connFailureCategory(f ConnFailure) Category {
+	switch f {
+	case ConnLost, ConnCannotConnect:
+		return CategoryUnavailable
+	case ConnMisconfigured:
+		return CategoryMisconfigured
+	}
+	return CategoryUnknown
+}
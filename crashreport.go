@@ -0,0 +1,234 @@
+package pqerror
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// CrashReport bundles what is needed to report an internal error upstream,
+// e.g. InternalError or DataCorrupted: all the fields of the error, the
+// server version and the query, with its literals redacted.
+type CrashReport struct {
+	ServerVersion    string       `json:"server_version,omitempty"`
+	Severity         string       `json:"severity,omitempty"`
+	Code             pq.ErrorCode `json:"code,omitempty"`
+	Condition        string       `json:"condition,omitempty"`
+	Message          string       `json:"message,omitempty"`
+	Detail           string       `json:"detail,omitempty"`
+	Hint             string       `json:"hint,omitempty"`
+	Position         string       `json:"position,omitempty"`
+	InternalPosition string       `json:"internal_position,omitempty"`
+	InternalQuery    string       `json:"internal_query,omitempty"`
+	Where            string       `json:"where,omitempty"`
+	Schema           string       `json:"schema,omitempty"`
+	Table            string       `json:"table,omitempty"`
+	Column           string       `json:"column,omitempty"`
+	DataTypeName     string       `json:"data_type_name,omitempty"`
+	Constraint       string       `json:"constraint,omitempty"`
+	File             string       `json:"file,omitempty"`
+	Line             string       `json:"line,omitempty"`
+	Routine          string       `json:"routine,omitempty"`
+	SourceURL        string       `json:"source_url,omitempty"`
+	Query            string       `json:"query,omitempty"`
+}
+
+// NewCrashReport returns the crash report of an error returned for a query by
+// a server of a given version, see SourceURL. The query and the internal
+// query are redacted by RedactQuery, so the positions of the error may no
+// longer point at the right characters, and so are the statements, the
+// parameters and the values quoted in the context. NewCrashReport returns nil
+// if there is no *pq.Error in the chain of the error.
+func NewCrashReport(err error, query, serverVersion string) *CrashReport {
+	pqerr, ok := asPqError(err)
+	if !ok {
+		return nil
+	}
+	url, _ := SourceURL(pqerr, serverVersion)
+	return &CrashReport{
+		ServerVersion:    serverVersion,
+		Severity:         pqerr.Severity,
+		Code:             pqerr.Code,
+		Condition:        ConditionName(pqerr.Code),
+		Message:          pqerr.Message,
+		Detail:           pqerr.Detail,
+		Hint:             pqerr.Hint,
+		Position:         pqerr.Position,
+		InternalPosition: pqerr.InternalPosition,
+		InternalQuery:    RedactQuery(pqerr.InternalQuery),
+		Where:            redactWhere(pqerr.Where),
+		Schema:           pqerr.Schema,
+		Table:            pqerr.Table,
+		Column:           pqerr.Column,
+		DataTypeName:     pqerr.DataTypeName,
+		Constraint:       pqerr.Constraint,
+		File:             pqerr.File,
+		Line:             pqerr.Line,
+		Routine:          pqerr.Routine,
+		SourceURL:        url,
+		Query:            RedactQuery(query),
+	}
+}
+
+var (
+	whereParamsRe = regexp.MustCompile(`^(.* with parameters: )(.*)$`)
+	whereValueRe  = regexp.MustCompile(`^(COPY .*, line \d+(?:, column .*?)?|JSON data, line \d+): .*$`)
+)
+
+// redactWhere redacts the context of an error frame by frame, see
+// ParseContext: the statements and expressions of PL/pgSQL and SQL
+// functions, e.g.
+//
+//	SQL statement "INSERT INTO audit VALUES ('jane@example.com')"
+//
+// even if they span several lines, the parameters of portals, and the values
+// of COPY and JSON data. The other lines, e.g. "PL/pgSQL function audit()
+// line 3 at SQL statement", are kept.
+func redactWhere(where string) string {
+	if where == "" {
+		return ""
+	}
+	lines := splitContext(where)
+	for i, line := range lines {
+		if m := contextStatementRe.FindStringIndex(line); m != nil {
+			sql := line[m[1]:]
+			if strings.HasSuffix(sql, `"`) {
+				lines[i] = line[:m[1]] + RedactQuery(sql[:len(sql)-1]) + `"`
+			} else {
+				lines[i] = line[:m[1]] + RedactQuery(sql)
+			}
+		} else if m := whereParamsRe.FindStringSubmatch(line); m != nil {
+			lines[i] = m[1] + RedactQuery(m[2])
+		} else if m := whereValueRe.FindStringSubmatch(line); m != nil {
+			lines[i] = m[1] + ": ?"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RedactQuery replaces the string and numeric literals of a query with "?",
+// e.g. "SELECT * FROM users WHERE email = 'jane@example.com' LIMIT 1" becomes
+// "SELECT * FROM users WHERE email = ? LIMIT ?". Quoted identifiers, comments
+// and parameters such as $1 are kept.
+func RedactQuery(query string) string {
+	var b strings.Builder
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i
+			} else {
+				end += 4
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case c == '"':
+			end := skipQuoted(query, i, '"', false)
+			b.WriteString(query[i:end])
+			i = end
+		case c == '\'':
+			escapes := false
+			// Drop the prefix of E'...', B'...', X'...' and N'...' strings.
+			if i > 0 && strings.IndexByte("EeBbXxNn", query[i-1]) >= 0 && (i == 1 || !isIdentByte(query[i-2])) {
+				escapes = query[i-1] == 'E' || query[i-1] == 'e'
+				s := b.String()
+				b.Reset()
+				b.WriteString(s[:len(s)-1])
+			}
+			b.WriteByte('?')
+			i = skipQuoted(query, i, '\'', escapes)
+		case c == '$' && (i == 0 || !isIdentByte(query[i-1])):
+			tag := dollarTag(query[i:])
+			if tag == "" {
+				b.WriteByte(c)
+				i++
+				break
+			}
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += len(tag) + end + len(tag)
+			}
+			b.WriteByte('?')
+		case ('0' <= c && c <= '9' || c == '.' && i+1 < len(query) && '0' <= query[i+1] && query[i+1] <= '9') &&
+			(i == 0 || !isIdentByte(query[i-1]) && query[i-1] != '$'):
+			i = skipNumber(query, i)
+			b.WriteByte('?')
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// skipQuoted returns the offset after the quoted string starting at
+// a given offset. Doubled quotes, and backslashes if escapes are enabled,
+// escape the quote.
+func skipQuoted(s string, i int, quote byte, escapes bool) int {
+	for i++; i < len(s); i++ {
+		switch {
+		case escapes && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// dollarTag returns the dollar-quote tag at the beginning of a string, e.g.
+// "$$" or "$body$", or an empty string if there is none.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80,
+			i > 1 && '0' <= c && c <= '9':
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// skipNumber returns the offset after the numeric literal starting at
+// a given offset, e.g. "42", "3.14" or "1e-5".
+func skipNumber(s string, i int) int {
+	for i < len(s) && ('0' <= s[i] && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && '0' <= s[j] && s[j] <= '9' {
+			i = j
+			for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c >= 0x80
+}
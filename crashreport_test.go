@@ -0,0 +1,190 @@
+package pqerror
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "strings and numbers",
+			query: "SELECT * FROM users WHERE email = 'jane@example.com' AND score > 3.5 LIMIT 10",
+			want:  "SELECT * FROM users WHERE email = ? AND score > ? LIMIT ?",
+		},
+		{
+			name:  "doubled quotes",
+			query: "INSERT INTO notes VALUES ('it''s', 'o''clock')",
+			want:  "INSERT INTO notes VALUES (?, ?)",
+		},
+		{
+			name:  "E-strings",
+			query: `SELECT E'it\'s', e'\\', E'a\nb' FROM t`,
+			want:  "SELECT ?, ?, ? FROM t",
+		},
+		{
+			name:  "backslashes in standard strings",
+			query: `SELECT 'C:\', 'x' FROM t`,
+			want:  "SELECT ?, ? FROM t",
+		},
+		{
+			name:  "bit, hex and national strings",
+			query: "SELECT B'1010', X'ff', N'text', U&'d\\0061t' FROM t",
+			want:  "SELECT ?, ?, ?, U&? FROM t",
+		},
+		{
+			name:  "identifiers ending in string prefixes",
+			query: "SELECT name'x' FROM t",
+			want:  "SELECT name? FROM t",
+		},
+		{
+			name:  "dollar quoting",
+			query: "SELECT $$it's$$, $body$ 'nested' $$ $body$ FROM t",
+			want:  "SELECT ?, ? FROM t",
+		},
+		{
+			name:  "unterminated dollar quoting",
+			query: "SELECT $tag$ secret",
+			want:  "SELECT ?",
+		},
+		{
+			name:  "parameters",
+			query: "UPDATE accounts SET balance = balance - $1 WHERE id = $2 AND version = 7",
+			want:  "UPDATE accounts SET balance = balance - $1 WHERE id = $2 AND version = ?",
+		},
+		{
+			name:  "multi-digit parameters",
+			query: "SELECT $10, $11::int",
+			want:  "SELECT $10, $11::int",
+		},
+		{
+			name:  "comments",
+			query: "SELECT 1 -- id 42, 'x'\n/* 'secret' 7 */ FROM t",
+			want:  "SELECT ? -- id 42, 'x'\n/* 'secret' 7 */ FROM t",
+		},
+		{
+			name:  "unterminated comment",
+			query: "SELECT 1 /* 'x'",
+			want:  "SELECT ? /* 'x'",
+		},
+		{
+			name:  "quoted identifiers",
+			query: `SELECT "col 1", "it's", t2.c3 FROM "Users"`,
+			want:  `SELECT "col 1", "it's", t2.c3 FROM "Users"`,
+		},
+		{
+			name:  "exponents",
+			query: "SELECT 1e-5, 2E10, .5",
+			want:  "SELECT ?, ?, ?",
+		},
+		{
+			name:  "unterminated string",
+			query: "SELECT 'secret",
+			want:  "SELECT ?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactQuery(tt.query); got != tt.want {
+				t.Errorf("RedactQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactWhere(t *testing.T) {
+	tests := []struct {
+		where string
+		want  string
+	}{
+		{"", ""},
+		{
+			"PL/pgSQL function transfer(integer,integer,numeric) line 5 at RAISE",
+			"PL/pgSQL function transfer(integer,integer,numeric) line 5 at RAISE",
+		},
+		{
+			"SQL statement \"INSERT INTO audit VALUES ('jane@example.com', 42)\"\nPL/pgSQL function audit() line 3 at SQL statement",
+			"SQL statement \"INSERT INTO audit VALUES (?, ?)\"\nPL/pgSQL function audit() line 3 at SQL statement",
+		},
+		{
+			"SQL expression \"balance - 100\"\nPL/pgSQL function withdraw(integer) line 4 at IF",
+			"SQL expression \"balance - ?\"\nPL/pgSQL function withdraw(integer) line 4 at IF",
+		},
+		{
+			"PL/pgSQL assignment \"secret := 'hunter2'\"",
+			"PL/pgSQL assignment \"secret := ?\"",
+		},
+		{
+			"PL/pgSQL expression \"x = 'secret'\"\nPL/pgSQL function check_x(text) line 4 at IF",
+			"PL/pgSQL expression \"x = ?\"\nPL/pgSQL function check_x(text) line 4 at IF",
+		},
+		{
+			"SQL statement \"UPDATE accounts\n    SET password = 'hunter2'\n    WHERE \"email\" = 'jane@example.com'\"\nPL/pgSQL function reset() line 2 at SQL statement",
+			"SQL statement \"UPDATE accounts\n    SET password = ?\n    WHERE \"email\" = ?\"\nPL/pgSQL function reset() line 2 at SQL statement",
+		},
+		{
+			"SQL statement \"SELECT \"name\"\n    FROM users WHERE token = 'abc123'\"",
+			"SQL statement \"SELECT \"name\"\n    FROM users WHERE token = ?\"",
+		},
+		{
+			"SQL statement \"SELECT 'secret\nFROM t",
+			"SQL statement \"SELECT ?",
+		},
+		{
+			"unnamed portal with parameters: $1 = 'jane@example.com', $2 = '42'",
+			"unnamed portal with parameters: $1 = ?, $2 = ?",
+		},
+		{
+			"COPY users, line 2, column email: \"jane@example.com\"",
+			"COPY users, line 2, column email: ?",
+		},
+		{
+			"COPY users, line 3: \"4,jane@example.com,x\"",
+			"COPY users, line 3: ?",
+		},
+		{
+			"JSON data, line 1: {\"password\": \"hunter2\",...",
+			"JSON data, line 1: ?",
+		},
+	}
+	for _, tt := range tests {
+		if got := redactWhere(tt.where); got != tt.want {
+			t.Errorf("redactWhere(%q) = %q, want %q", tt.where, got, tt.want)
+		}
+	}
+}
+
+func TestNewCrashReport(t *testing.T) {
+	err := fmt.Errorf("audit: %w", &pq.Error{
+		Severity:      "ERROR",
+		Code:          InternalError,
+		Message:       "unexpected chunk number 2 (expected 1) for toast value 16438",
+		InternalQuery: "SELECT payload FROM audit WHERE id = 7",
+		Where:         "SQL statement \"INSERT INTO audit VALUES ('jane@example.com')\"\nPL/pgSQL function audit() line 3 at SQL statement",
+	})
+	r := NewCrashReport(err, "SELECT audit('jane@example.com')", "16.2")
+	if r == nil {
+		t.Fatal("NewCrashReport() = nil")
+	}
+	if want := "SELECT audit(?)"; r.Query != want {
+		t.Errorf("Query = %q, want %q", r.Query, want)
+	}
+	if want := "SELECT payload FROM audit WHERE id = ?"; r.InternalQuery != want {
+		t.Errorf("InternalQuery = %q, want %q", r.InternalQuery, want)
+	}
+	if want := "SQL statement \"INSERT INTO audit VALUES (?)\"\nPL/pgSQL function audit() line 3 at SQL statement"; r.Where != want {
+		t.Errorf("Where = %q, want %q", r.Where, want)
+	}
+	if r.Condition != "internal_error" || r.ServerVersion != "16.2" {
+		t.Errorf("Condition, ServerVersion = %q, %q", r.Condition, r.ServerVersion)
+	}
+	if NewCrashReport(fmt.Errorf("boom"), "SELECT 1", "16.2") != nil {
+		t.Errorf("NewCrashReport() of a non-pq error is not nil")
+	}
+}
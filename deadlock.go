@@ -0,0 +1,265 @@
+package pqerror
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Deadlock is the wait-for graph of a deadlock, parsed from the DETAIL field
+// of a DeadlockDetected error, e.g.
+//
+//	Process 123 waits for ShareLock on transaction 456; blocked by process 789.
+//	Process 789 waits for ShareLock on transaction 455; blocked by process 123.
+//
+// The server sends the queries of the processes to its log only, where they
+// follow the waits, e.g.
+//
+//	Process 123: UPDATE accounts SET balance = balance - 10 WHERE id = 2
+//	Process 789: UPDATE accounts SET balance = balance + 10 WHERE id = 1
+type Deadlock struct {
+	// Waits lists the edges of the graph, in the order the server reported
+	// them.
+	Waits []LockWait
+	// Queries maps process IDs to their queries, if known.
+	Queries map[int]string
+}
+
+// LockWait is an edge of a wait-for graph: a process waiting for a lock
+// held by another process.
+type LockWait struct {
+	PID int
+	// Mode is the requested lock mode, e.g. "ShareLock".
+	Mode      string
+	Target    LockTarget
+	BlockedBy int
+}
+
+// LockKind is the kind of object a lock is taken on.
+type LockKind int
+
+const (
+	LockUnknown LockKind = iota
+	LockRelation
+	LockExtend
+	LockPage
+	LockTuple
+	LockTransaction
+	LockVirtualTransaction
+	LockSpeculativeToken
+	LockObject
+	LockUser
+	LockAdvisory
+)
+
+var lockKindNames = [...]string{
+	LockUnknown:            "unknown",
+	LockRelation:           "relation",
+	LockExtend:             "extend",
+	LockPage:               "page",
+	LockTuple:              "tuple",
+	LockTransaction:        "transaction",
+	LockVirtualTransaction: "virtualxid",
+	LockSpeculativeToken:   "spectoken",
+	LockObject:             "object",
+	LockUser:               "userlock",
+	LockAdvisory:           "advisory",
+}
+
+// String returns the name of the kind as used by the locktype column of
+// pg_locks.
+func (k LockKind) String() string {
+	if k >= 0 && int(k) < len(lockKindNames) {
+		return lockKindNames[k]
+	}
+	return "LockKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// LockTarget is the object a lock is taken on. Only the fields relevant to
+// its kind are set.
+type LockTarget struct {
+	Kind LockKind
+	// Relation and Database are the OIDs of the relation and database of
+	// relation, extend, page, tuple and object locks. For object locks,
+	// Relation is the OID of the catalog, i.e. the class of the object.
+	Relation uint32
+	Database uint32
+	// Page and Tuple locate the page of page locks and the tuple of tuple
+	// locks.
+	Page  uint32
+	Tuple uint32
+	// Transaction is the transaction ID of transaction and speculative token
+	// locks.
+	Transaction uint32
+	// ID is the virtual transaction ID of virtual transaction locks, e.g.
+	// "3/1234", the token of speculative token locks, the OID of object
+	// locks and the fields of user and advisory locks, e.g. "[1,2,3,4]".
+	ID string
+	// Text is the description of the target sent by the server, e.g.
+	// "transaction 456".
+	Text string
+}
+
+// DeadlockOf returns the wait-for graph of a DeadlockDetected error. It
+// reports false if there is no such *pq.Error in the chain of the error or if
+// its DETAIL field cannot be parsed.
+func DeadlockOf(err error) (*Deadlock, bool) {
+	pqerr, ok := asPqError(err)
+	if !ok || pqerr.Code != DeadlockDetected {
+		return nil, false
+	}
+	d, err := ParseDeadlock(pqerr.Detail)
+	return d, err == nil
+}
+
+var (
+	deadlockWaitRe  = regexp.MustCompile(`^Process (\d+) waits for (\w+) on (.+); blocked by process (\d+)\.$`)
+	deadlockQueryRe = regexp.MustCompile(`^Process (\d+): (.*)$`)
+)
+
+// ParseDeadlock parses the detail of a DeadlockDetected error, as sent to
+// the client or as written to the server log, where the continuation lines
+// are indented with a tab.
+func ParseDeadlock(detail string) (*Deadlock, error) {
+	d := &Deadlock{Queries: make(map[int]string)}
+	last := 0 // the process whose query is being read
+	for _, line := range strings.Split(strings.Replace(detail, "\r\n", "\n", -1), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" && last == 0 {
+			continue
+		}
+		if m := deadlockWaitRe.FindStringSubmatch(trimmed); m != nil && last == 0 {
+			pid, _ := strconv.Atoi(m[1])
+			blocker, _ := strconv.Atoi(m[4])
+			d.Waits = append(d.Waits, LockWait{
+				PID:       pid,
+				Mode:      m[2],
+				Target:    parseLockTarget(m[3]),
+				BlockedBy: blocker,
+			})
+			continue
+		}
+		if m := deadlockQueryRe.FindStringSubmatch(trimmed); m != nil {
+			last, _ = strconv.Atoi(m[1])
+			d.Queries[last] = m[2]
+			continue
+		}
+		if last == 0 {
+			return nil, fmt.Errorf("pqerror: unexpected deadlock detail line %q", line)
+		}
+		// The query spans several lines, whose indentation is kept but for the
+		// tab of the log.
+		d.Queries[last] += "\n" + strings.TrimPrefix(line, "\t")
+	}
+	if len(d.Waits) == 0 {
+		return nil, fmt.Errorf("pqerror: no waits in deadlock detail")
+	}
+	return d, nil
+}
+
+var (
+	lockRelationRe    = regexp.MustCompile(`^relation (\d+) of database (\d+)$`)
+	lockExtendRe      = regexp.MustCompile(`^extension of relation (\d+) of database (\d+)$`)
+	lockPageRe        = regexp.MustCompile(`^page (\d+) of relation (\d+) of database (\d+)$`)
+	lockTupleRe       = regexp.MustCompile(`^tuple \((\d+),(\d+)\) of relation (\d+) of database (\d+)$`)
+	lockTransactionRe = regexp.MustCompile(`^transaction (\d+)$`)
+	lockVirtualRe     = regexp.MustCompile(`^virtual transaction (\d+/\d+)$`)
+	lockSpecTokenRe   = regexp.MustCompile(`^speculative token (\d+) of transaction (\d+)$`)
+	lockObjectRe      = regexp.MustCompile(`^object (\d+) of class (\d+) of database (\d+)$`)
+	lockUserRe        = regexp.MustCompile(`^user lock (\[[\d,]+\])$`)
+	lockAdvisoryRe    = regexp.MustCompile(`^advisory lock (\[[\d,]+\])$`)
+)
+
+// parseLockTarget parses a lock target as described by DescribeLockTag of
+// the server.
+func parseLockTarget(s string) LockTarget {
+	t := LockTarget{Text: s}
+	oid := func(s string) uint32 {
+		n, _ := strconv.ParseUint(s, 10, 32)
+		return uint32(n)
+	}
+	if m := lockRelationRe.FindStringSubmatch(s); m != nil {
+		t.Kind, t.Relation, t.Database = LockRelation, oid(m[1]), oid(m[2])
+	} else if m := lockExtendRe.FindStringSubmatch(s); m != nil {
+		t.Kind, t.Relation, t.Database = LockExtend, oid(m[1]), oid(m[2])
+	} else if m := lockPageRe.FindStringSubmatch(s); m != nil {
+		t.Kind, t.Page, t.Relation, t.Database = LockPage, oid(m[1]), oid(m[2]), oid(m[3])
+	} else if m := lockTupleRe.FindStringSubmatch(s); m != nil {
+		t.Kind, t.Page, t.Tuple, t.Relation, t.Database = LockTuple, oid(m[1]), oid(m[2]), oid(m[3]), oid(m[4])
+	} else if m := lockTransactionRe.FindStringSubmatch(s); m != nil {
+		t.Kind, t.Transaction = LockTransaction, oid(m[1])
+	} else if m := lockVirtualRe.FindStringSubmatch(s); m != nil {
+		t.Kind, t.ID = LockVirtualTransaction, m[1]
+	} else if m := lockSpecTokenRe.FindStringSubmatch(s); m != nil {
+		t.Kind, t.ID, t.Transaction = LockSpeculativeToken, m[1], oid(m[2])
+	} else if m := lockObjectRe.FindStringSubmatch(s); m != nil {
+		t.Kind, t.ID, t.Relation, t.Database = LockObject, m[1], oid(m[2]), oid(m[3])
+	} else if m := lockUserRe.FindStringSubmatch(s); m != nil {
+		t.Kind, t.ID = LockUser, m[1]
+	} else if m := lockAdvisoryRe.FindStringSubmatch(s); m != nil {
+		t.Kind, t.ID = LockAdvisory, m[1]
+	}
+	return t
+}
+
+// PIDs returns the IDs of the processes of the deadlock in ascending order.
+func (d *Deadlock) PIDs() []int {
+	seen := make(map[int]bool)
+	var pids []int
+	for _, w := range d.Waits {
+		for _, pid := range []int{w.PID, w.BlockedBy} {
+			if !seen[pid] {
+				seen[pid] = true
+				pids = append(pids, pid)
+			}
+		}
+	}
+	sort.Ints(pids)
+	return pids
+}
+
+// String renders the deadlock as text, e.g.
+//
+//	process 123 waits for ShareLock on transaction 456, blocked by process 789
+//	process 789 waits for ShareLock on transaction 455, blocked by process 123
+//	process 123: UPDATE accounts SET balance = balance - 10 WHERE id = 2
+//	process 789: UPDATE accounts SET balance = balance + 10 WHERE id = 1
+func (d *Deadlock) String() string {
+	var b strings.Builder
+	for _, w := range d.Waits {
+		fmt.Fprintf(&b, "process %d waits for %s on %s, blocked by process %d\n", w.PID, w.Mode, w.Target.Text, w.BlockedBy)
+	}
+	for _, pid := range d.PIDs() {
+		if q, ok := d.Queries[pid]; ok {
+			fmt.Fprintf(&b, "process %d: %s\n", pid, q)
+		}
+	}
+	return b.String()
+}
+
+// DOT renders the deadlock as a graph in the DOT language of Graphviz, with
+// a node per process, labeled with its query if known, and an edge per wait.
+func (d *Deadlock) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph deadlock {\n")
+	for _, pid := range d.PIDs() {
+		label := "process " + strconv.Itoa(pid)
+		if q, ok := d.Queries[pid]; ok {
+			label += "\n" + q
+		}
+		fmt.Fprintf(&b, "\tp%d [label=%s];\n", pid, dotQuote(label))
+	}
+	for _, w := range d.Waits {
+		fmt.Fprintf(&b, "\tp%d -> p%d [label=%s];\n", w.PID, w.BlockedBy, dotQuote(w.Mode+" on "+w.Target.Text))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\l`, "\r", "")
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `\l"`
+}
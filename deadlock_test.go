@@ -0,0 +1,148 @@
+package pqerror
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+const (
+	clientDeadlockDetail = "" +
+		"Process 123 waits for ShareLock on transaction 456; blocked by process 789.\n" +
+		"Process 789 waits for ShareLock on transaction 455; blocked by process 123."
+
+	logDeadlockDetail = "" +
+		"Process 123 waits for ShareLock on transaction 456; blocked by process 789.\n" +
+		"\tProcess 789 waits for ShareLock on transaction 455; blocked by process 123.\n" +
+		"\tProcess 123: UPDATE accounts SET balance = balance - 10 WHERE id = 2\n" +
+		"\tProcess 789: UPDATE accounts\n" +
+		"\t    SET balance = balance + 10\n" +
+		"\t    WHERE id = 1"
+)
+
+func TestParseDeadlock(t *testing.T) {
+	waits := []LockWait{
+		{PID: 123, Mode: "ShareLock", Target: LockTarget{Kind: LockTransaction, Transaction: 456, Text: "transaction 456"}, BlockedBy: 789},
+		{PID: 789, Mode: "ShareLock", Target: LockTarget{Kind: LockTransaction, Transaction: 455, Text: "transaction 455"}, BlockedBy: 123},
+	}
+	tests := []struct {
+		name   string
+		detail string
+		want   *Deadlock
+	}{
+		{
+			name:   "client",
+			detail: clientDeadlockDetail,
+			want:   &Deadlock{Waits: waits, Queries: map[int]string{}},
+		},
+		{
+			name:   "log",
+			detail: logDeadlockDetail,
+			want: &Deadlock{Waits: waits, Queries: map[int]string{
+				123: "UPDATE accounts SET balance = balance - 10 WHERE id = 2",
+				789: "UPDATE accounts\n    SET balance = balance + 10\n    WHERE id = 1",
+			}},
+		},
+		{
+			name:   "CRLF",
+			detail: "Process 123 waits for ShareLock on transaction 456; blocked by process 789.\r\n\tProcess 789 waits for ShareLock on transaction 455; blocked by process 123.\r\n",
+			want:   &Deadlock{Waits: waits, Queries: map[int]string{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDeadlock(tt.detail)
+			if err != nil {
+				t.Fatalf("ParseDeadlock() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDeadlock() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDeadlockErrors(t *testing.T) {
+	for _, detail := range []string{
+		"",
+		"Process 123: SELECT 1",
+		"Process 123 waits for ShareLock on transaction 456.",
+	} {
+		if _, err := ParseDeadlock(detail); err == nil {
+			t.Errorf("ParseDeadlock(%q) succeeded, want error", detail)
+		}
+	}
+}
+
+func TestParseLockTarget(t *testing.T) {
+	tests := []LockTarget{
+		{Kind: LockRelation, Relation: 16384, Database: 5, Text: "relation 16384 of database 5"},
+		{Kind: LockExtend, Relation: 16384, Database: 5, Text: "extension of relation 16384 of database 5"},
+		{Kind: LockPage, Page: 7, Relation: 16384, Database: 5, Text: "page 7 of relation 16384 of database 5"},
+		{Kind: LockTuple, Page: 0, Tuple: 12, Relation: 16384, Database: 5, Text: "tuple (0,12) of relation 16384 of database 5"},
+		{Kind: LockTransaction, Transaction: 4294967295, Text: "transaction 4294967295"},
+		{Kind: LockVirtualTransaction, ID: "3/1234", Text: "virtual transaction 3/1234"},
+		{Kind: LockSpeculativeToken, ID: "2", Transaction: 731, Text: "speculative token 2 of transaction 731"},
+		{Kind: LockObject, ID: "16390", Relation: 1259, Database: 5, Text: "object 16390 of class 1259 of database 5"},
+		{Kind: LockUser, ID: "[1,2,3,4]", Text: "user lock [1,2,3,4]"},
+		{Kind: LockAdvisory, ID: "[16384,0,42,1]", Text: "advisory lock [16384,0,42,1]"},
+		{Kind: LockUnknown, Text: "pg_database.datfrozenxid of database 5"},
+		{Kind: LockUnknown, Text: "relation of database 5"},
+	}
+	for _, want := range tests {
+		if got := parseLockTarget(want.Text); got != want {
+			t.Errorf("parseLockTarget(%q) = %+v, want %+v", want.Text, got, want)
+		}
+	}
+}
+
+func TestDeadlockOf(t *testing.T) {
+	d, ok := DeadlockOf(&pq.Error{Code: DeadlockDetected, Detail: clientDeadlockDetail})
+	if !ok || len(d.Waits) != 2 {
+		t.Errorf("DeadlockOf() = %+v, %v", d, ok)
+	}
+	if _, ok := DeadlockOf(&pq.Error{Code: SerializationFailure, Detail: clientDeadlockDetail}); ok {
+		t.Errorf("DeadlockOf() of SerializationFailure reported true")
+	}
+	if _, ok := DeadlockOf(&pq.Error{Code: DeadlockDetected, Detail: "See server log for query details."}); ok {
+		t.Errorf("DeadlockOf() of unparsable detail reported true")
+	}
+}
+
+func TestDeadlockString(t *testing.T) {
+	d, err := ParseDeadlock(logDeadlockDetail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"process 123 waits for ShareLock on transaction 456, blocked by process 789\n" +
+		"process 789 waits for ShareLock on transaction 455, blocked by process 123\n" +
+		"process 123: UPDATE accounts SET balance = balance - 10 WHERE id = 2\n" +
+		"process 789: UPDATE accounts\n" +
+		"    SET balance = balance + 10\n" +
+		"    WHERE id = 1\n"
+	if got := d.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDeadlockDOT(t *testing.T) {
+	d, err := ParseDeadlock(clientDeadlockDetail + "\n" +
+		"Process 123: UPDATE t SET name = 'a \"b\" \\\\c' WHERE id = 2\n" +
+		"Process 789: UPDATE t SET name = 'x'\n" +
+		"  WHERE id = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph deadlock {
+	p123 [label="process 123\lUPDATE t SET name = 'a \"b\" \\\\c' WHERE id = 2\l"];
+	p789 [label="process 789\lUPDATE t SET name = 'x'\l  WHERE id = 1\l"];
+	p123 -> p789 [label="ShareLock on transaction 456\l"];
+	p789 -> p123 [label="ShareLock on transaction 455\l"];
+}
+`
+	if got := d.DOT(); got != want {
+		t.Errorf("DOT() =\n%s\nwant\n%s", got, want)
+	}
+}
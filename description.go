@@ -0,0 +1,347 @@
+package pqerror
+
+import "github.com/lib/pq"
+
+// codeDescriptions explains the codes of the catalog: when the server raises
+// them and, where it helps, what usually causes them.
+var codeDescriptions = map[pq.ErrorCode]string{
+	// Class 00 - Successful Completion
+	SuccessfulCompletion: "The statement completed successfully.",
+
+	// Class 01 - Warning
+	Warning:                                 "A generic warning, e.g. raised by RAISE WARNING without a specific code.",
+	WarningDynamicResultSetsReturned:        "A procedure returned dynamic result sets.",
+	WarningImplicitZeroBitPadding:           "A bit string was padded with zeros to fit the target type.",
+	WarningNullValueEliminatedInSetFunction: "An aggregate function ignored NULL values.",
+	WarningPrivilegeNotGranted:              "GRANT did not grant some of the requested privileges, usually because the grantor lacks the grant option.",
+	WarningPrivilegeNotRevoked:              "REVOKE did not revoke some of the requested privileges, usually because the grantor lacks the grant option.",
+	WarningStringDataRightTruncation:        "A string was truncated to fit the target type.",
+	WarningDeprecatedFeature:                "The statement uses a feature that is deprecated and may be removed in a future release.",
+
+	// Class 02 - No Data
+	NoData:                                "The statement did not find any data, e.g. a FETCH past the last row.",
+	NoAdditionalDynamicResultSetsReturned: "No more dynamic result sets are available.",
+
+	// Class 03 - SQL Statement Not Yet Complete
+	SQLStatementNotYetComplete: "The statement cannot run before a previous statement has completed.",
+
+	// Class 08 - Connection Exception
+	ConnectionException:                           "The connection failed for an unspecified reason.",
+	ConnectionDoesNotExist:                        "The connection does not exist, e.g. a dblink connection that was never opened.",
+	ConnectionFailure:                             "The connection was lost, e.g. the socket was closed or a replication connection failed.",
+	SQLClientUnableToEstablishSQLConnection:       "The client could not establish a connection, e.g. the host cannot be reached.",
+	SQLServerRejectedEstablishmentOfSQLConnection: "The server rejected the connection.",
+	TransactionResolutionUnknown:                  "The connection was lost while a transaction was being committed, so its outcome is unknown.",
+	ProtocolViolation:                             "The client sent a message that violates the frontend/backend protocol, e.g. a malformed or unexpected message.",
+
+	// Class 09 - Triggered Action Exception
+	TriggeredActionException: "A trigger failed.",
+
+	// Class 0A - Feature Not Supported
+	FeatureNotSupported: "The statement uses a feature PostgreSQL does not support, or does not support in this context.",
+
+	// Class 0B - Invalid Transaction Initiation
+	InvalidTransactionInitiation: "A transaction cannot be started here, e.g. from within a function.",
+
+	// Class 0F - Locator Exception
+	LocatorException:            "A large object locator is invalid.",
+	InvalidLocatorSpecification: "A large object locator has an invalid specification.",
+
+	// Class 0L - Invalid Grantor
+	InvalidGrantor:        "The grantor of a privilege is invalid.",
+	InvalidGrantOperation: "The grant operation is invalid, e.g. granting a role to itself.",
+
+	// Class 0P - Invalid Role Specification
+	InvalidRoleSpecification: "A role specification is invalid, e.g. a reserved role name or a role that cannot be used here.",
+
+	// Class 0Z - Diagnostics Exception
+	DiagnosticsException:                           "A diagnostics statement failed.",
+	StackedDiagnosticsAccessedWithoutActiveHandler: "GET STACKED DIAGNOSTICS was used outside of an exception handler.",
+
+	// Class 20 - Case Not Found
+	CaseNotFound: "No branch of a PL/pgSQL CASE statement matched and there is no ELSE branch.",
+
+	// Class 21 - Cardinality Violation
+	CardinalityViolation: "A subquery used as an expression returned more than one row, or an ON CONFLICT DO UPDATE affected a row twice.",
+
+	// Class 22 - Data Exception
+	DataException:                         "A value is invalid for an unspecified reason.",
+	ArraySubscriptError:                   "An array subscript is out of range or an array has the wrong dimensions.",
+	CharacterNotInRepertoire:              "A character is invalid in the encoding, e.g. invalid UTF-8 bytes.",
+	DatetimeFieldOverflow:                 "A date or time field is out of range, e.g. month 13 or a day that does not exist.",
+	DivisionByZero:                        "A number was divided by zero.",
+	ErrorInAssignment:                     "A value could not be assigned to the target.",
+	EscapeCharacterConflict:               "An escape character conflicts with another special character.",
+	IndicatorOverflow:                     "An indicator parameter overflowed.",
+	IntervalFieldOverflow:                 "An interval field is out of range.",
+	InvalidArgumentForLogarithm:           "The argument of a logarithm is zero or negative.",
+	InvalidArgumentForNtileFunction:       "The argument of ntile is not positive.",
+	InvalidArgumentForNthValueFunction:    "The argument of nth_value is not positive.",
+	InvalidArgumentForPowerFunction:       "The arguments of power are invalid, e.g. zero raised to a negative power.",
+	InvalidArgumentForWidthBucketFunction: "The arguments of width_bucket are invalid, e.g. an empty range or a non-positive bucket count.",
+	InvalidCharacterValueForCast:          "A string cannot be cast to the target type.",
+	InvalidDatetimeFormat:                 "A date or time string does not match the expected format.",
+	InvalidEscapeCharacter:                "An escape character is invalid, e.g. longer than one character in LIKE ... ESCAPE.",
+	InvalidEscapeOctet:                    "An escape octet is invalid.",
+	InvalidEscapeSequence:                 "An escape sequence is invalid, e.g. a trailing backslash in a LIKE pattern or an invalid Unicode escape.",
+	NonstandardUseOfEscapeCharacter:       "A backslash was used in an ordinary string literal while standard_conforming_strings is off and escape_string_warning is on.",
+	InvalidIndicatorParameterValue:        "An indicator parameter has an invalid value.",
+	InvalidParameterValue:                 "A parameter or argument has an invalid value, e.g. an unknown option or a negative size.",
+	InvalidRegularExpression:              "A regular expression is invalid.",
+	InvalidRowCountInLimitClause:          "The row count of LIMIT or FETCH FIRST is negative.",
+	InvalidRowCountInResultOffsetClause:   "The row count of OFFSET is negative.",
+	InvalidTablesampleArgument:            "An argument of TABLESAMPLE is invalid, e.g. a percentage out of 0-100.",
+	InvalidTablesampleRepeat:              "The REPEATABLE seed of TABLESAMPLE is invalid.",
+	InvalidTimeZoneDisplacementValue:      "A time zone displacement is out of range.",
+	InvalidUseOfEscapeCharacter:           "An escape character was used where it is not allowed.",
+	MostSpecificTypeMismatch:              "A value does not match the most specific type expected.",
+	NullValueNotAllowed:                   "A NULL value was given where none is allowed, e.g. assigned to a PL/pgSQL variable declared NOT NULL.",
+	NullValueNoIndicatorParameter:         "A NULL value was returned without an indicator parameter.",
+	NumericValueOutOfRange:                "A numeric value is out of the range of its type, e.g. an integer overflow.",
+	StringDataLengthMismatch:              "A string has the wrong length for a fixed-length type.",
+	StringDataRightTruncation:             "A string is too long for the target type, e.g. for a varchar(n) column.",
+	SubstringError:                        "The arguments of substring are invalid, e.g. a negative length.",
+	TrimError:                             "The arguments of trim are invalid.",
+	UnterminatedCString:                   "A C string is not terminated.",
+	ZeroLengthCharacterString:             "A string is empty where an empty string is not allowed.",
+	FloatingPointException:                "A floating-point operation failed, e.g. it overflowed or underflowed.",
+	InvalidTextRepresentation:             "A string is not a valid input for the type, e.g. 'abc' for an integer or a malformed UUID.",
+	InvalidBinaryRepresentation:           "A binary value is not a valid input for the type, e.g. in a binary COPY or a binary parameter.",
+	BadCopyFileFormat:                     "The data of COPY is malformed, e.g. a row has too many or too few columns.",
+	UntranslatableCharacter:               "A character has no equivalent in the target encoding.",
+	NotAnXmlDocument:                      "An XML value is not a well-formed document.",
+	InvalidXmlDocument:                    "An XML document is invalid.",
+	InvalidXmlContent:                     "An XML content is invalid.",
+	InvalidXmlComment:                     "An XML comment is invalid.",
+	InvalidXmlProcessingInstruction:       "An XML processing instruction is invalid.",
+	DuplicateJsonObjectKeyValue:           "A JSON object has a duplicate key where keys must be unique.",
+	InvalidJsonText:                       "A string is not valid JSON.",
+	InvalidJsonSubscript:                  "A subscript of an SQL/JSON path is invalid.",
+	MoreThanOneJsonItem:                   "An SQL/JSON path returned more than one item where one is expected.",
+	NoJsonItem:                            "An SQL/JSON path returned no item where one is expected.",
+	NonNumericJsonItem:                    "An SQL/JSON item is not numeric where a number is expected.",
+	NonUniqueKeysInJsonObject:             "A JSON object has duplicate keys where keys must be unique.",
+	SingletonJsonItemRequired:             "An SQL/JSON path requires a single item.",
+	JsonArrayNotFound:                     "An SQL/JSON path expected an array.",
+	JsonMemberNotFound:                    "An SQL/JSON path refers to an object member that does not exist.",
+	JsonNumberNotFound:                    "An SQL/JSON path expected a number.",
+	JsonObjectNotFound:                    "An SQL/JSON path expected an object.",
+	JsonScalarRequired:                    "An SQL/JSON path expected a scalar.",
+	TooManyJsonArrayElements:              "A JSON array has too many elements.",
+	TooManyJsonObjectMembers:              "A JSON object has too many members.",
+
+	// Class 23 - Integrity Constraint Violation
+	IntegrityConstraintViolation: "An integrity constraint was violated.",
+	RestrictViolation:            "A row cannot be deleted or updated because a foreign key declared ON DELETE or ON UPDATE RESTRICT refers to it.",
+	NotNullViolation:             "A NULL value was written to a column declared NOT NULL.",
+	ForeignKeyViolation:          "A row refers to a row of another table that does not exist, or a row that other rows refer to was deleted or updated.",
+	UniqueViolation:              "A row duplicates the key of another row in a unique index or a primary key.",
+	CheckViolation:               "A row does not satisfy a check constraint, or a value does not satisfy the check constraint of a domain.",
+	ExclusionViolation:           "A row conflicts with another row according to an exclusion constraint, e.g. overlapping ranges.",
+
+	// Class 24 - Invalid Cursor State
+	InvalidCursorState: "A cursor is in the wrong state for the operation, e.g. fetching from a cursor that is not open.",
+
+	// Class 25 - Invalid Transaction State
+	InvalidTransactionState:                         "The operation is not allowed in the current transaction state.",
+	ActiveSQLTransaction:                            "The statement cannot run inside a transaction block, e.g. VACUUM or CREATE DATABASE.",
+	BranchTransactionAlreadyActive:                  "A branch transaction is already active.",
+	HeldCursorRequiresSameIsolationLevel:            "A held cursor requires the same isolation level.",
+	InappropriateAccessModeForBranchTransaction:     "The access mode is inappropriate for a branch transaction.",
+	InappropriateIsolationLevelForBranchTransaction: "The isolation level is inappropriate for a branch transaction.",
+	NoActiveSQLTransactionForBranchTransaction:      "There is no active transaction for a branch transaction.",
+	ReadOnlySQLTransaction:                          "The statement writes in a read-only transaction, e.g. on a hot standby or with default_transaction_read_only.",
+	SchemaAndDataStatementMixingNotSupported:        "Schema and data statements cannot be mixed in the transaction.",
+	NoActiveSQLTransaction:                          "The statement requires a transaction block, e.g. SAVEPOINT outside of one.",
+	InFailedSQLTransaction:                          "An earlier statement of the transaction failed, so commands are ignored until the end of the transaction block.",
+	IdleInTransactionSessionTimeout:                 "The session was terminated because it stayed idle in a transaction longer than idle_in_transaction_session_timeout.",
+
+	// Class 26 - Invalid SQL Statement Name
+	InvalidSQLStatementName: "A prepared statement does not exist.",
+
+	// Class 27 - Triggered Data Change Violation
+	TriggeredDataChangeViolation: "A trigger changed data in a way that is not allowed, e.g. a row was changed twice in one statement.",
+
+	// Class 28 - Invalid Authorization Specification
+	InvalidAuthorizationSpecification: "Authentication failed or the role is not allowed to connect, e.g. no pg_hba.conf entry matches.",
+	InvalidPassword:                   "Password authentication failed.",
+
+	// Class 2B - Dependent Privilege Descriptors Still Exist
+	DependentPrivilegeDescriptorsStillExist: "Privileges cannot be revoked because other privileges depend on them.",
+	DependentObjectsStillExist:              "An object cannot be dropped because other objects depend on it; use CASCADE to drop them too.",
+
+	// Class 2D - Invalid Transaction Termination
+	InvalidTransactionTermination: "A transaction cannot be ended here, e.g. COMMIT inside a function or a procedure called within a transaction block.",
+
+	// Class 2F - SQL Routine Exception
+	SQLRoutineException:               "A routine failed.",
+	FunctionExecutedNoReturnStatement: "A PL/pgSQL function reached its end without a RETURN statement.",
+	ModifyingSQLDataNotPermitted:      "A routine tried to modify data where it is not permitted.",
+	ProhibitedSQLStatementAttempted:   "A routine tried to run a prohibited statement.",
+	ReadingSQLDataNotPermitted:        "A routine tried to read data where it is not permitted.",
+
+	// Class 34 - Invalid Cursor Name
+	InvalidCursorName: "A cursor does not exist.",
+
+	// Class 38 - External Routine Exception
+	ExternalRoutineException:                                 "An external routine failed.",
+	ExternalRoutineException_ContainingSQLNotPermitted:       "A routine declared NO SQL executed SQL.",
+	ExternalRoutineException_ModifyingSQLDataNotPermitted:    "A routine not declared MODIFIES SQL DATA modified data.",
+	ExternalRoutineException_ProhibitedSQLStatementAttempted: "A routine executed a statement it is not allowed to run.",
+	ExternalRoutineException_ReadingSQLDataNotPermitted:      "A routine not declared READS SQL DATA read data.",
+
+	// Class 39 - External Routine Invocation Exception
+	ExternalRoutineInvocationException:                              "An external routine could not be invoked, or a trigger or set-returning function was called in the wrong context.",
+	ExternalRoutineInvocationException_InvalidSQLstateReturned:      "A routine raised an error with a malformed SQLSTATE.",
+	ExternalRoutineInvocationException_NullValueNotAllowed:          "A routine returned NULL where the caller does not accept it, e.g. a PL/Python function.",
+	ExternalRoutineInvocationException_TriggerProtocolViolated:      "A trigger function was called outside a trigger or returned a value of the wrong type.",
+	ExternalRoutineInvocationException_SrfProtocolViolated:          "A set-returning function was called in a context that cannot accept a set.",
+	ExternalRoutineInvocationException_EventTriggerProtocolViolated: "An event trigger function was called outside an event trigger.",
+
+	// Class 3B - Savepoint Exception
+	SavepointException:            "A savepoint operation failed.",
+	InvalidSavepointSpecification: "A savepoint does not exist.",
+
+	// Class 3D - Invalid Catalog Name
+	InvalidCatalogName: "A database does not exist.",
+
+	// Class 3F - Invalid Schema Name
+	InvalidSchemaName: "A schema does not exist, or no schema has been selected to create in.",
+
+	// Class 40 - Transaction Rollback
+	TransactionRollback:                     "The transaction was rolled back.",
+	TransactionIntegrityConstraintViolation: "A deferred constraint was violated at commit.",
+	SerializationFailure:                    "The transaction conflicts with a concurrent transaction and was rolled back; retrying it may succeed.",
+	StatementCompletionUnknown:              "The outcome of the statement is unknown; it may or may not have completed.",
+	DeadlockDetected:                        "The transaction was rolled back to break a deadlock with other transactions; retrying it may succeed.",
+
+	// Class 42 - Syntax Error or Access Rule Violation
+	SyntaxErrorOrAccessRuleViolation:   "The statement is invalid.",
+	SyntaxError:                        "The statement has a syntax error.",
+	InsufficientPrivilege:              "The role lacks the privileges the statement requires, or row-level security forbids it.",
+	CannotCoerce:                       "A value cannot be cast to the target type.",
+	GroupingError:                      "A column must appear in the GROUP BY clause or be used in an aggregate function, or an aggregate is used where it is not allowed.",
+	WindowingError:                     "A window function is used where it is not allowed, or a window definition is invalid.",
+	InvalidRecursion:                   "A recursive query is invalid, e.g. it refers to itself in a non-recursive term.",
+	InvalidForeignKey:                  "A foreign key is invalid, e.g. the referenced columns lack a unique constraint.",
+	InvalidName:                        "A name is invalid.",
+	NameTooLong:                        "A name is too long.",
+	ReservedName:                       "A name is reserved, e.g. a system column name.",
+	DatatypeMismatch:                   "A value has the wrong type, e.g. a column is of one type but the expression is of another.",
+	IndeterminateDatatype:              "The type of a parameter or literal cannot be determined.",
+	CollationMismatch:                  "Collations conflict, e.g. two implicit collations in a comparison.",
+	IndeterminateCollation:             "The collation of an expression cannot be determined.",
+	WrongObjectType:                    "An object has the wrong type for the statement, e.g. ALTER TABLE on a view.",
+	UndefinedColumn:                    "A column does not exist.",
+	UndefinedFunction:                  "A function or operator with the given name and argument types does not exist.",
+	UndefinedTable:                     "A table, view or other relation does not exist, or is not in the search_path.",
+	UndefinedParameter:                 "A parameter does not exist, e.g. $2 in a statement given one parameter.",
+	UndefinedObject:                    "An object does not exist, e.g. a type, role, extension or configuration parameter.",
+	DuplicateColumn:                    "A column already exists, or the same column is specified twice.",
+	DuplicateCursor:                    "A cursor already exists.",
+	DuplicateDatabase:                  "A database already exists.",
+	DuplicateFunction:                  "A function with the same argument types already exists.",
+	DuplicatePreparedStatement:         "A prepared statement already exists.",
+	DuplicateSchema:                    "A schema already exists.",
+	DuplicateTable:                     "A table or other relation already exists.",
+	DuplicateAlias:                     "A table name or alias is specified more than once.",
+	DuplicateObject:                    "An object already exists, e.g. a constraint, role, type or index.",
+	AmbiguousColumn:                    "A column reference matches columns of several tables.",
+	AmbiguousFunction:                  "A function call matches several functions.",
+	AmbiguousParameter:                 "A parameter reference is ambiguous.",
+	AmbiguousAlias:                     "An alias is ambiguous.",
+	InvalidColumnReference:             "A column reference is invalid, e.g. an ON CONFLICT target without a matching unique index.",
+	InvalidColumnDefinition:            "A column definition is invalid.",
+	InvalidCursorDefinition:            "A cursor definition is invalid.",
+	InvalidDatabaseDefinition:          "A database definition is invalid.",
+	InvalidFunctionDefinition:          "A function definition is invalid, e.g. its return type does not match its body.",
+	InvalidPreparedStatementDefinition: "A prepared statement definition is invalid.",
+	InvalidSchemaDefinition:            "A schema definition is invalid.",
+	InvalidTableDefinition:             "A table definition is invalid, e.g. multiple primary keys or an invalid partition bound.",
+	InvalidObjectDefinition:            "An object definition is invalid.",
+
+	// Class 44 - WITH CHECK OPTION Violation
+	WithCheckOptionViolation: "A row written through a view does not satisfy the view's WITH CHECK OPTION, or violates a row-level security policy.",
+
+	// Class 53 - Insufficient Resources
+	InsufficientResources:      "The server lacks a resource.",
+	DiskFull:                   "The server ran out of disk space.",
+	OutOfMemory:                "The server ran out of memory, or a shared memory area is full.",
+	TooManyConnections:         "The server reached max_connections, or the role or database reached its connection limit.",
+	ConfigurationLimitExceeded: "A configured limit was exceeded, e.g. max_locks_per_transaction or max_pred_locks_per_transaction.",
+
+	// Class 54 - Program Limit Exceeded
+	ProgramLimitExceeded: "A limit of PostgreSQL was exceeded, e.g. an index row is too large.",
+	StatementTooComplex:  "The statement is too complex, e.g. its expressions are nested too deeply.",
+	TooManyColumns:       "A table or result has too many columns.",
+	TooManyArguments:     "A function has too many arguments.",
+
+	// Class 55 - Object Not In Prerequisite State
+	ObjectNotInPrerequisiteState: "An object is not in the state the statement requires, e.g. a sequence's currval before nextval.",
+	ObjectInUse:                  "An object is in use, e.g. a database has other sessions connected to it.",
+	CantChangeRuntimeParam:       "A parameter cannot be changed at this time, e.g. one that requires a restart.",
+	LockNotAvailable:             "A lock could not be acquired, either with NOWAIT or within lock_timeout.",
+	UnsafeNewEnumValueUsage:      "A new enum value cannot be used before the transaction that added it commits.",
+
+	// Class 57 - Operator Intervention
+	OperatorIntervention: "An administrator intervened.",
+	QueryCanceled:        "The statement was canceled, e.g. by statement_timeout, a cancel request or a conflict with recovery.",
+	AdminShutdown:        "The server is shutting down, or the session was terminated, e.g. by pg_terminate_backend.",
+	CrashShutdown:        "The session was terminated because another server process crashed.",
+	CannotConnectNow:     "The server is starting up, shutting down or in recovery and does not accept connections yet.",
+	DatabaseDropped:      "The database was dropped while the session was connected to it.",
+
+	// Class 58 - System Error
+	SystemError:   "A system error occurred outside of PostgreSQL.",
+	IoError:       "An I/O error occurred, e.g. reading or writing a file failed.",
+	UndefinedFile: "A file does not exist, e.g. the file of an extension or a relation.",
+	DuplicateFile: "A file already exists.",
+
+	// Class 72 - Snapshot Failure
+	SnapshotTooOld: "The snapshot is too old to read data that vacuum may have removed, see old_snapshot_threshold.",
+
+	// Class F0 - Configuration File Error
+	ConfigFileError: "A configuration file is invalid.",
+	LockFileExists:  "A lock file already exists, e.g. another server is running on the same data directory.",
+
+	// Class HV - Foreign Data Wrapper Error (SQL/MED)
+	FdwError:                             "A foreign data wrapper failed.",
+	FdwColumnNameNotFound:                "A foreign data wrapper could not find a column.",
+	FdwDynamicParameterValueNeeded:       "A foreign data wrapper needs a dynamic parameter value.",
+	FdwFunctionSequenceError:             "A foreign data wrapper called functions in the wrong order.",
+	FdwInconsistentDescriptorInformation: "A foreign data wrapper descriptor is inconsistent.",
+	FdwInvalidAttributeValue:             "A foreign data wrapper attribute has an invalid value.",
+	FdwInvalidColumnName:                 "A foreign data wrapper column name is invalid.",
+	FdwInvalidColumnNumber:               "A foreign data wrapper column number is invalid.",
+	FdwInvalidDataType:                   "A foreign data wrapper data type is invalid.",
+	FdwInvalidDataTypeDescriptors:        "A foreign data wrapper data type descriptor is invalid.",
+	FdwInvalidDescriptorFieldIdentifier:  "A foreign data wrapper descriptor field identifier is invalid.",
+	FdwInvalidHandle:                     "A foreign data wrapper handle is invalid.",
+	FdwInvalidOptionIndex:                "A foreign data wrapper option index is invalid.",
+	FdwInvalidOptionName:                 "A foreign data wrapper option name is invalid, e.g. an unknown option of a foreign server or table.",
+	FdwInvalidStringLengthOrBufferLength: "A foreign data wrapper string or buffer length is invalid.",
+	FdwInvalidStringFormat:               "A foreign data wrapper string format is invalid.",
+	FdwInvalidUseOfNullPointer:           "A foreign data wrapper used a null pointer.",
+	FdwTooManyHandles:                    "A foreign data wrapper has too many handles.",
+	FdwOutOfMemory:                       "A foreign data wrapper ran out of memory.",
+	FdwNoSchemas:                         "A foreign data wrapper found no schemas.",
+	FdwOptionNameNotFound:                "A foreign data wrapper option does not exist.",
+	FdwReplyHandle:                       "A foreign data wrapper reply handle is invalid.",
+	FdwSchemaNotFound:                    "A foreign data wrapper could not find a schema.",
+	FdwTableNotFound:                     "A foreign data wrapper could not find a table.",
+	FdwUnableToCreateExecution:           "A foreign data wrapper could not create an execution.",
+	FdwUnableToCreateReply:               "A foreign data wrapper could not create a reply.",
+	FdwUnableToEstablishConnection:       "A foreign data wrapper could not connect to the remote server, e.g. postgres_fdw or dblink.",
+
+	// Class P0 - PL/pgSQL Error
+	PLpgSQLError:   "A PL/pgSQL function failed.",
+	RaiseException: "A PL/pgSQL RAISE EXCEPTION without a specific code.",
+	NoDataFound:    "A PL/pgSQL SELECT INTO STRICT returned no rows.",
+	TooManyRows:    "A PL/pgSQL SELECT INTO STRICT returned more than one row.",
+	AssertFailure:  "A PL/pgSQL ASSERT failed.",
+
+	// Class XX - Internal Error
+	InternalError:  "An internal error occurred, i.e. a bug in PostgreSQL or an extension, or data corruption.",
+	DataCorrupted:  "Data is corrupted, e.g. a page of a table failed verification.",
+	IndexCorrupted: "An index is corrupted; it needs to be rebuilt with REINDEX.",
+}
@@ -0,0 +1,149 @@
+package pqerror
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Verbosity is the verbosity of error reports, see FormatError. It mirrors
+// the VERBOSITY variable of psql.
+type Verbosity int
+
+const (
+	// VerbosityDefault shows the primary message, the cursor display and the
+	// DETAIL, HINT, QUERY and CONTEXT fields.
+	VerbosityDefault Verbosity = iota
+	// VerbosityTerse shows the primary message only.
+	VerbosityTerse
+	// VerbosityVerbose shows all the fields, including the code and the
+	// LOCATION field.
+	VerbosityVerbose
+	// VerbositySQLState shows the code only.
+	VerbositySQLState
+)
+
+var verbosityNames = [...]string{
+	VerbosityDefault:  "default",
+	VerbosityTerse:    "terse",
+	VerbosityVerbose:  "verbose",
+	VerbositySQLState: "sqlstate",
+}
+
+func (v Verbosity) String() string {
+	if v >= 0 && int(v) < len(verbosityNames) {
+		return verbosityNames[v]
+	}
+	return "Verbosity(" + strconv.Itoa(int(v)) + ")"
+}
+
+// ParseVerbosity parses the name of a verbosity, e.g. "verbose".
+func ParseVerbosity(s string) (Verbosity, error) {
+	for v, name := range verbosityNames {
+		if strings.EqualFold(s, name) {
+			return Verbosity(v), nil
+		}
+	}
+	return 0, fmt.Errorf("pqerror: unknown verbosity %q", s)
+}
+
+// FormatError formats an error the way psql prints it at a given verbosity,
+// e.g.
+//
+//	ERROR:  23505: duplicate key value violates unique constraint "users_email_key"
+//	DETAIL:  Key (email)=(jane@example.com) already exists.
+//	SCHEMA NAME:  public
+//	TABLE NAME:  users
+//	CONSTRAINT NAME:  users_email_key
+//	LOCATION:  _bt_check_unique, nbtinsert.c:570
+//
+// The query is the statement the error was returned for. It is used to show
+// the cursor display of the Position of the error and may be empty. As psql
+// does, the CONTEXT field is shown for errors only, not for notices. Errors
+// without a *pq.Error in their chain are formatted by their Error method.
+// The result ends with a newline, or is empty for a nil error.
+func FormatError(err error, query string, verbosity Verbosity) string {
+	if err == nil {
+		return ""
+	}
+	pqerr, ok := asPqError(err)
+	if !ok {
+		return err.Error() + "\n"
+	}
+
+	var b strings.Builder
+	if pqerr.Severity != "" {
+		b.WriteString(pqerr.Severity + ":  ")
+	}
+	if verbosity == VerbositySQLState {
+		if pqerr.Code != "" {
+			b.WriteString(string(pqerr.Code) + "\n")
+			return b.String()
+		}
+		verbosity = VerbosityTerse
+	}
+	if verbosity == VerbosityVerbose && pqerr.Code != "" {
+		b.WriteString(string(pqerr.Code) + ": ")
+	}
+	if pqerr.Message != "" {
+		b.WriteString(pqerr.Message)
+	} else {
+		b.WriteString("no primary error message")
+	}
+
+	var (
+		queryText string
+		queryPos  int
+	)
+	switch {
+	case pqerr.Position != "":
+		if verbosity != VerbosityTerse && query != "" {
+			queryText = query
+			queryPos, _ = strconv.Atoi(pqerr.Position)
+		} else {
+			b.WriteString(" at character " + pqerr.Position)
+		}
+	case pqerr.InternalPosition != "":
+		if verbosity != VerbosityTerse && pqerr.InternalQuery != "" {
+			queryText = pqerr.InternalQuery
+			queryPos, _ = strconv.Atoi(pqerr.InternalPosition)
+		} else {
+			b.WriteString(" at character " + pqerr.InternalPosition)
+		}
+	}
+	b.WriteByte('\n')
+
+	if verbosity != VerbosityTerse {
+		writePosition(&b, queryText, queryPos)
+		writeField(&b, "DETAIL", pqerr.Detail)
+		writeField(&b, "HINT", pqerr.Hint)
+		writeField(&b, "QUERY", pqerr.InternalQuery)
+		if SeverityOf(pqerr).IsError() {
+			writeField(&b, "CONTEXT", pqerr.Where)
+		}
+	}
+	if verbosity == VerbosityVerbose {
+		writeField(&b, "SCHEMA NAME", pqerr.Schema)
+		writeField(&b, "TABLE NAME", pqerr.Table)
+		writeField(&b, "COLUMN NAME", pqerr.Column)
+		writeField(&b, "DATATYPE NAME", pqerr.DataTypeName)
+		writeField(&b, "CONSTRAINT NAME", pqerr.Constraint)
+		if pqerr.Routine != "" || pqerr.File != "" || pqerr.Line != "" {
+			b.WriteString("LOCATION:  ")
+			if pqerr.Routine != "" {
+				b.WriteString(pqerr.Routine + ", ")
+			}
+			if pqerr.File != "" && pqerr.Line != "" {
+				b.WriteString(pqerr.File + ":" + pqerr.Line)
+			}
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func writeField(b *strings.Builder, label, value string) {
+	if value != "" {
+		b.WriteString(label + ":  " + value + "\n")
+	}
+}
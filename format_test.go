@@ -0,0 +1,226 @@
+package pqerror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// The expected outputs are those of psql 16 with VERBOSITY set accordingly
+// and SHOW_CONTEXT set to errors, its default.
+func TestFormatError(t *testing.T) {
+	unique := &pq.Error{
+		Severity:   "ERROR",
+		Code:       UniqueViolation,
+		Message:    `duplicate key value violates unique constraint "users_email_key"`,
+		Detail:     "Key (email)=(jane@example.com) already exists.",
+		Schema:     "public",
+		Table:      "users",
+		Constraint: "users_email_key",
+		Routine:    "_bt_check_unique",
+		File:       "nbtinsert.c",
+		Line:       "666",
+	}
+	syntax := &pq.Error{
+		Severity: "ERROR",
+		Code:     SyntaxError,
+		Message:  `syntax error at or near "FORM"`,
+		Position: "10",
+		Routine:  "scanner_yyerror",
+		File:     "scan.l",
+		Line:     "1241",
+	}
+	internal := &pq.Error{
+		Severity:         "ERROR",
+		Code:             UndefinedColumn,
+		Message:          `column "balanse" does not exist`,
+		InternalQuery:    "SELECT balanse FROM accounts",
+		InternalPosition: "8",
+		Where:            "PL/pgSQL function total() line 3 at RETURN",
+	}
+	notice := &pq.Error{
+		Severity: "NOTICE",
+		Code:     "00000",
+		Message:  "table users was vacuumed",
+		Hint:     "Nothing to do.",
+		Where:    "PL/pgSQL function maintain() line 5 at RAISE",
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		query     string
+		verbosity Verbosity
+		want      string
+	}{
+		{
+			name:      "default",
+			err:       unique,
+			verbosity: VerbosityDefault,
+			want: "" +
+				"ERROR:  duplicate key value violates unique constraint \"users_email_key\"\n" +
+				"DETAIL:  Key (email)=(jane@example.com) already exists.\n",
+		},
+		{
+			name:      "terse",
+			err:       unique,
+			verbosity: VerbosityTerse,
+			want:      "ERROR:  duplicate key value violates unique constraint \"users_email_key\"\n",
+		},
+		{
+			name:      "verbose",
+			err:       fmt.Errorf("create user: %w", unique),
+			verbosity: VerbosityVerbose,
+			want: "" +
+				"ERROR:  23505: duplicate key value violates unique constraint \"users_email_key\"\n" +
+				"DETAIL:  Key (email)=(jane@example.com) already exists.\n" +
+				"SCHEMA NAME:  public\n" +
+				"TABLE NAME:  users\n" +
+				"CONSTRAINT NAME:  users_email_key\n" +
+				"LOCATION:  _bt_check_unique, nbtinsert.c:666\n",
+		},
+		{
+			name:      "sqlstate",
+			err:       unique,
+			verbosity: VerbositySQLState,
+			want:      "ERROR:  23505\n",
+		},
+		{
+			name:      "sqlstate without code",
+			err:       &pq.Error{Severity: "ERROR", Message: "connection lost", Detail: "The server went away."},
+			verbosity: VerbositySQLState,
+			want:      "ERROR:  connection lost\n",
+		},
+		{
+			name:      "cursor",
+			err:       syntax,
+			query:     "SELECT * FORM users",
+			verbosity: VerbosityDefault,
+			want: "" +
+				"ERROR:  syntax error at or near \"FORM\"\n" +
+				"LINE 1: SELECT * FORM users\n" +
+				"                 ^\n",
+		},
+		{
+			name:      "cursor verbose",
+			err:       syntax,
+			query:     "SELECT * FORM users",
+			verbosity: VerbosityVerbose,
+			want: "" +
+				"ERROR:  42601: syntax error at or near \"FORM\"\n" +
+				"LINE 1: SELECT * FORM users\n" +
+				"                 ^\n" +
+				"LOCATION:  scanner_yyerror, scan.l:1241\n",
+		},
+		{
+			name:      "position terse",
+			err:       syntax,
+			query:     "SELECT * FORM users",
+			verbosity: VerbosityTerse,
+			want:      "ERROR:  syntax error at or near \"FORM\" at character 10\n",
+		},
+		{
+			name:      "position without query",
+			err:       syntax,
+			verbosity: VerbosityDefault,
+			want:      "ERROR:  syntax error at or near \"FORM\" at character 10\n",
+		},
+		{
+			name:      "sqlstate fallback with position",
+			err:       &pq.Error{Severity: "ERROR", Message: `syntax error at or near "FORM"`, Position: "10"},
+			query:     "SELECT * FORM users",
+			verbosity: VerbositySQLState,
+			want:      "ERROR:  syntax error at or near \"FORM\" at character 10\n",
+		},
+		{
+			name:      "internal query",
+			err:       internal,
+			query:     "SELECT total()",
+			verbosity: VerbosityDefault,
+			want: "" +
+				"ERROR:  column \"balanse\" does not exist\n" +
+				"LINE 1: SELECT balanse FROM accounts\n" +
+				"               ^\n" +
+				"QUERY:  SELECT balanse FROM accounts\n" +
+				"CONTEXT:  PL/pgSQL function total() line 3 at RETURN\n",
+		},
+		{
+			name:      "internal query terse",
+			err:       internal,
+			query:     "SELECT total()",
+			verbosity: VerbosityTerse,
+			want:      "ERROR:  column \"balanse\" does not exist at character 8\n",
+		},
+		{
+			name:      "notice without context",
+			err:       notice,
+			verbosity: VerbosityDefault,
+			want: "" +
+				"NOTICE:  table users was vacuumed\n" +
+				"HINT:  Nothing to do.\n",
+		},
+		{
+			name:      "notice verbose without context",
+			err:       notice,
+			verbosity: VerbosityVerbose,
+			want: "" +
+				"NOTICE:  00000: table users was vacuumed\n" +
+				"HINT:  Nothing to do.\n",
+		},
+		{
+			name:      "location without file",
+			err:       &pq.Error{Severity: "ERROR", Code: RaiseException, Message: "insufficient funds", Routine: "exec_stmt_raise"},
+			verbosity: VerbosityVerbose,
+			want: "" +
+				"ERROR:  P0001: insufficient funds\n" +
+				"LOCATION:  exec_stmt_raise, \n",
+		},
+		{
+			name:      "location without routine",
+			err:       &pq.Error{Severity: "ERROR", Code: RaiseException, Message: "insufficient funds", File: "pl_exec.c", Line: "3907"},
+			verbosity: VerbosityVerbose,
+			want: "" +
+				"ERROR:  P0001: insufficient funds\n" +
+				"LOCATION:  pl_exec.c:3907\n",
+		},
+		{
+			name:      "no primary message",
+			err:       &pq.Error{Severity: "ERROR", Code: InternalError},
+			verbosity: VerbosityDefault,
+			want:      "ERROR:  no primary error message\n",
+		},
+		{
+			name:      "non-pq error",
+			err:       errors.New("dial tcp: connection refused"),
+			verbosity: VerbosityVerbose,
+			want:      "dial tcp: connection refused\n",
+		},
+		{
+			name: "nil",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatError(tt.err, tt.query, tt.verbosity); got != tt.want {
+				t.Errorf("FormatError() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVerbosity(t *testing.T) {
+	for _, v := range []Verbosity{VerbosityDefault, VerbosityTerse, VerbosityVerbose, VerbositySQLState} {
+		if got, err := ParseVerbosity(v.String()); err != nil || got != v {
+			t.Errorf("ParseVerbosity(%q) = %v, %v, want %v", v, got, err, v)
+		}
+	}
+	if got, err := ParseVerbosity("VERBOSE"); err != nil || got != VerbosityVerbose {
+		t.Errorf("ParseVerbosity(VERBOSE) = %v, %v", got, err)
+	}
+	if _, err := ParseVerbosity("loud"); err == nil {
+		t.Errorf("ParseVerbosity(loud) succeeded, want error")
+	}
+}
@@ -2,4 +2,4 @@ module github.com/michaljemala/pqerror
 
 go 1.13
 
-require github.com/lib/pq v1.3.0
+require github.com/lib/pq v1.10.9
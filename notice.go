@@ -0,0 +1,261 @@
+package pqerror
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+// NoticeHandler handles a notice sent by the server, e.g. a deprecation
+// warning of class 01 or a message raised by RAISE NOTICE. The context is the
+// one of the statement the notice was received while executing. Handlers are
+// called synchronously, the connection is blocked until they return.
+type NoticeHandler func(ctx context.Context, notice *pq.Error)
+
+// NoticeConnector is a driver.Connector capturing the notices the server
+// sends on its connections, e.g.
+//
+//	c, err := pq.NewConnector(dsn)
+//	...
+//	db := sql.OpenDB(pqerror.NewNoticeConnector(c, logNotice))
+//	...
+//	ctx, rec := pqerror.WithNoticeRecorder(ctx)
+//	_, err = db.ExecContext(ctx, "SELECT deprecated_function()")
+//	for _, n := range rec.Notices() {
+//		...
+//	}
+//
+// Every notice is passed to the handler of the connector, if any, and to the
+// NoticeRecorder of the context of the statement it was received while
+// executing, if any. Notices received while iterating over the rows of
+// a query are attributed to the context of the query. To record all notices
+// of a single connection, execute all its statements with the same
+// NoticeRecorder, see sql.DB.Conn.
+//
+// The connections returned by a NoticeConnector wrap the pq connections, so
+// they cannot be passed to the pq functions expecting a pq connection, e.g.
+// pq.SetNoticeHandler.
+type NoticeConnector struct {
+	driver.Connector
+	handler NoticeHandler
+}
+
+// NewNoticeConnector returns a connector capturing the notices sent on the
+// connections of a given connector, which must return pq connections, e.g.
+// a connector returned by pq.NewConnector. The handler may be nil.
+func NewNoticeConnector(c driver.Connector, handler NoticeHandler) *NoticeConnector {
+	return &NoticeConnector{Connector: c, handler: handler}
+}
+
+// Connect implements driver.Connector.
+func (c *NoticeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The context of Connect is the one of the request the connection was
+	// opened for, or none at all if opened by the pool in the background,
+	// so it is not kept: the statements set their own.
+	nc := &noticeConn{Conn: conn, handler: c.handler, ctx: context.Background()}
+	setNoticeHandler(conn, nc.notice)
+	return nc, nil
+}
+
+// setNoticeHandler is pq.SetNoticeHandler, replaced by tests using fake
+// connections.
+var setNoticeHandler = pq.SetNoticeHandler
+
+// NoticeRecorder records the notices received while executing statements
+// with a context, see WithNoticeRecorder. It is safe for concurrent use.
+type NoticeRecorder struct {
+	mu      sync.Mutex
+	notices []*pq.Error
+}
+
+// Notices returns the notices recorded so far.
+func (r *NoticeRecorder) Notices() []*pq.Error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*pq.Error(nil), r.notices...)
+}
+
+// Reset discards the notices recorded so far.
+func (r *NoticeRecorder) Reset() {
+	r.mu.Lock()
+	r.notices = nil
+	r.mu.Unlock()
+}
+
+func (r *NoticeRecorder) record(notice *pq.Error) {
+	r.mu.Lock()
+	r.notices = append(r.notices, notice)
+	r.mu.Unlock()
+}
+
+type noticeRecorderKey struct{}
+
+// WithNoticeRecorder returns a context recording the notices received on the
+// connections of a NoticeConnector while executing statements with it.
+func WithNoticeRecorder(ctx context.Context) (context.Context, *NoticeRecorder) {
+	r := new(NoticeRecorder)
+	return context.WithValue(ctx, noticeRecorderKey{}, r), r
+}
+
+// NoticeRecorderFrom returns the NoticeRecorder of a context, or nil if there
+// is none.
+func NoticeRecorderFrom(ctx context.Context) *NoticeRecorder {
+	r, _ := ctx.Value(noticeRecorderKey{}).(*NoticeRecorder)
+	return r
+}
+
+// noticeConn is a connection attributing the notices it receives to the
+// context of the statement being executed.
+type noticeConn struct {
+	driver.Conn
+	handler NoticeHandler
+
+	mu  sync.Mutex
+	ctx context.Context
+}
+
+// use sets the context the notices received from now on are attributed to.
+func (c *noticeConn) use(ctx context.Context) {
+	c.mu.Lock()
+	c.ctx = ctx
+	c.mu.Unlock()
+}
+
+func (c *noticeConn) notice(notice *pq.Error) {
+	c.mu.Lock()
+	ctx := c.ctx
+	c.mu.Unlock()
+	if r := NoticeRecorderFrom(ctx); r != nil {
+		r.record(notice)
+	}
+	if c.handler != nil {
+		c.handler(ctx, notice)
+	}
+}
+
+func (c *noticeConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	c.use(ctx)
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &noticeStmt{Stmt: stmt, conn: c}, nil
+}
+
+func (c *noticeConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *noticeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.use(ctx)
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bc.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *noticeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	c.use(ctx)
+	return ec.ExecContext(ctx, query, args)
+}
+
+func (c *noticeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	c.use(ctx)
+	return qc.QueryContext(ctx, query, args)
+}
+
+func (c *noticeConn) Ping(ctx context.Context) error {
+	c.use(ctx)
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter. The sql package calls it
+// with the context of the request a pooled connection is handed out to, so
+// the notices received before its first statement are attributed to it.
+func (c *noticeConn) ResetSession(ctx context.Context) error {
+	c.use(ctx)
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implements driver.Validator.
+func (c *noticeConn) IsValid() bool {
+	if v, ok := c.Conn.(interface{ IsValid() bool }); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+type noticeStmt struct {
+	driver.Stmt
+	conn *noticeConn
+}
+
+func (s *noticeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	s.conn.use(ctx)
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return ec.ExecContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+func (s *noticeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	s.conn.use(ctx)
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return qc.QueryContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values)
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("pqerror: driver does not support the use of named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,48 @@
+//go:build go1.21
+// +build go1.21
+
+package pqerror
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/lib/pq"
+)
+
+// SlogNoticeHandler returns a notice handler logging the notices to a logger.
+// Warnings are logged at the warning level, notices and informational
+// messages at the info level and everything else at the debug level.
+func SlogNoticeHandler(logger *slog.Logger) NoticeHandler {
+	return func(ctx context.Context, notice *pq.Error) {
+		attrs := []slog.Attr{
+			slog.String("severity", notice.Severity),
+			slog.String("code", string(notice.Code)),
+		}
+		for _, f := range []struct{ key, value string }{
+			{"detail", notice.Detail},
+			{"hint", notice.Hint},
+			{"where", notice.Where},
+			{"schema", notice.Schema},
+			{"table", notice.Table},
+			{"column", notice.Column},
+			{"constraint", notice.Constraint},
+		} {
+			if f.value != "" {
+				attrs = append(attrs, slog.String(f.key, f.value))
+			}
+		}
+		logger.LogAttrs(ctx, noticeLevel(SeverityOf(notice)), notice.Message, attrs...)
+	}
+}
+
+func noticeLevel(s Severity) slog.Level {
+	switch {
+	case s.AtLeast(SeverityWarning):
+		return slog.LevelWarn
+	case s.AtLeast(SeverityInfo):
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
@@ -0,0 +1,196 @@
+package pqerror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// fakeNoticeConn is a connection implementing only the mandatory methods of
+// driver.Conn and driver.Stmt, so the fallbacks of noticeConn are taken. Every
+// statement it executes sends a notice whose message is the query.
+type fakeNoticeConn struct {
+	mu     sync.Mutex
+	notice func(*pq.Error)
+}
+
+func (c *fakeNoticeConn) send(msg string) {
+	c.mu.Lock()
+	notice := c.notice
+	c.mu.Unlock()
+	notice(&pq.Error{Severity: pq.Enotice, Code: "00000", Message: msg})
+}
+
+func (c *fakeNoticeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeNoticeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeNoticeConn) Close() error              { return nil }
+func (c *fakeNoticeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeNoticeStmt struct {
+	conn  *fakeNoticeConn
+	query string
+}
+
+func (s *fakeNoticeStmt) Close() error  { return nil }
+func (s *fakeNoticeStmt) NumInput() int { return -1 }
+
+func (s *fakeNoticeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.send(s.query)
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeNoticeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.send(s.query)
+	return &fakeNoticeRows{}, nil
+}
+
+type fakeNoticeRows struct{}
+
+func (r *fakeNoticeRows) Columns() []string              { return []string{"x"} }
+func (r *fakeNoticeRows) Close() error                   { return nil }
+func (r *fakeNoticeRows) Next(dest []driver.Value) error { return io.EOF }
+
+type fakeNoticeConnector struct {
+	conn *fakeNoticeConn
+}
+
+func (c *fakeNoticeConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c *fakeNoticeConnector) Driver() driver.Driver                        { return nil }
+
+// useFakeNoticeHandler makes the connectors accept fake connections. It
+// returns a function restoring the pq notice handler setter.
+func useFakeNoticeHandler() func() {
+	orig := setNoticeHandler
+	setNoticeHandler = func(conn driver.Conn, handler func(*pq.Error)) {
+		c := conn.(*fakeNoticeConn)
+		c.mu.Lock()
+		c.notice = handler
+		c.mu.Unlock()
+	}
+	return func() { setNoticeHandler = orig }
+}
+
+func noticeMessages(notices []*pq.Error) []string {
+	var msgs []string
+	for _, n := range notices {
+		msgs = append(msgs, n.Message)
+	}
+	return msgs
+}
+
+func TestNoticeConnector(t *testing.T) {
+	defer useFakeNoticeHandler()()
+	type handled struct {
+		msg      string
+		recorder *NoticeRecorder
+	}
+	var (
+		mu      sync.Mutex
+		handler []handled
+	)
+	fake := &fakeNoticeConn{}
+	db := sql.OpenDB(NewNoticeConnector(&fakeNoticeConnector{conn: fake}, func(ctx context.Context, n *pq.Error) {
+		mu.Lock()
+		handler = append(handler, handled{n.Message, NoticeRecorderFrom(ctx)})
+		mu.Unlock()
+	}))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	// The connection is opened for the first request, but its context is
+	// not kept once the request is done.
+	ctx1, rec1 := WithNoticeRecorder(context.Background())
+	if _, err := db.ExecContext(ctx1, "exec"); err != nil {
+		t.Fatal(err)
+	}
+	ctx2, rec2 := WithNoticeRecorder(context.Background())
+	rows, err := db.QueryContext(ctx2, "query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	// The sql package resets the connection with the context of the next
+	// request before it executes any statement.
+	ctx3, rec3 := WithNoticeRecorder(context.Background())
+	conn, err := db.Conn(ctx3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.send("async")
+	conn.Close()
+
+	if got, want := noticeMessages(rec1.Notices()), []string{"exec"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first recorder = %q, want %q", got, want)
+	}
+	if got, want := noticeMessages(rec2.Notices()), []string{"query"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second recorder = %q, want %q", got, want)
+	}
+	if got, want := noticeMessages(rec3.Notices()), []string{"async"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("third recorder = %q, want %q", got, want)
+	}
+	want := []handled{{"exec", rec1}, {"query", rec2}, {"async", rec3}}
+	if !reflect.DeepEqual(handler, want) {
+		t.Errorf("handler got %+v, want %+v", handler, want)
+	}
+}
+
+func TestNoticeConnectorIgnoresConnectContext(t *testing.T) {
+	defer useFakeNoticeHandler()()
+	fake := &fakeNoticeConn{}
+	ctx, rec := WithNoticeRecorder(context.Background())
+	conn, err := NewNoticeConnector(&fakeNoticeConnector{conn: fake}, nil).Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.send("before statement")
+	if n := rec.Notices(); len(n) != 0 {
+		t.Errorf("Connect context recorded %q", noticeMessages(n))
+	}
+
+	// A statement prepared without a context records to the context it is
+	// executed with.
+	stmt, err := conn.Prepare("prepared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx2, rec2 := WithNoticeRecorder(context.Background())
+	if _, err := stmt.(driver.StmtExecContext).ExecContext(ctx2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := noticeMessages(rec2.Notices()), []string{"prepared"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("statement recorder = %q, want %q", got, want)
+	}
+	if _, err := stmt.(driver.StmtExecContext).ExecContext(ctx2, []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(1)}}); err == nil {
+		t.Errorf("ExecContext() with named parameters succeeded")
+	}
+}
+
+func TestNoticeRecorder(t *testing.T) {
+	if NoticeRecorderFrom(context.Background()) != nil {
+		t.Errorf("NoticeRecorderFrom() of an empty context is not nil")
+	}
+	ctx, rec := WithNoticeRecorder(context.Background())
+	if NoticeRecorderFrom(ctx) != rec {
+		t.Errorf("NoticeRecorderFrom() is not the recorder of the context")
+	}
+	rec.record(&pq.Error{Message: "a"})
+	notices := rec.Notices()
+	notices[0] = nil
+	if got := noticeMessages(rec.Notices()); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("Notices() = %q, not a copy", got)
+	}
+	rec.Reset()
+	if n := rec.Notices(); len(n) != 0 {
+		t.Errorf("Notices() after Reset() = %q", noticeMessages(n))
+	}
+}
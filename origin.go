@@ -0,0 +1,80 @@
+package pqerror
+
+import (
+	"strconv"
+
+	"github.com/lib/pq"
+)
+
+// Origin tells who defines a code according to the structure of SQLSTATE
+// laid down by the SQL standard: classes and subclasses beginning with 0-4 or
+// A-H are reserved for the standard, the others are left to implementations.
+type Origin int
+
+const (
+	// OriginUnknown is the origin of strings that are not valid SQLSTATEs.
+	OriginUnknown Origin = iota
+	// OriginStandard is the origin of the codes defined by the SQL standard,
+	// e.g. DivisionByZero (22012) or FdwError (HV000).
+	OriginStandard
+	// OriginPostgreSQL is the origin of the codes PostgreSQL defines in the
+	// P subclasses of standard classes, e.g. InvalidTextRepresentation
+	// (22P02) or DeadlockDetected (40P01).
+	OriginPostgreSQL
+	// OriginImplementationDefined is the origin of the codes of the
+	// implementation-defined classes, e.g. RaiseException (P0001) or
+	// InternalError (XX000), and of the other implementation-defined
+	// subclasses of standard classes, e.g. SyntaxError (42601). Note that
+	// this includes the codes of most constraint violations, i.e.
+	// NotNullViolation (23502), ForeignKeyViolation (23503), UniqueViolation
+	// (23505) and CheckViolation (23514), as the standard only defines
+	// IntegrityConstraintViolation (23000) and RestrictViolation (23001).
+	OriginImplementationDefined
+)
+
+var originNames = [...]string{
+	OriginUnknown:               "unknown",
+	OriginStandard:              "standard",
+	OriginPostgreSQL:            "postgresql",
+	OriginImplementationDefined: "implementation_defined",
+}
+
+func (o Origin) String() string {
+	if o >= 0 && int(o) < len(originNames) {
+		return originNames[o]
+	}
+	return "Origin(" + strconv.Itoa(int(o)) + ")"
+}
+
+// OriginOf returns the origin of a code. Only codes of OriginStandard can be
+// expected to be reported the same way by other SQL engines.
+//
+// Note that class HV is a standard class defined by SQL/MED, whereas class
+// F0, although in the standard range, is defined by PostgreSQL.
+func OriginOf(code pq.ErrorCode) Origin {
+	if !validSQLState(string(code)) {
+		return OriginUnknown
+	}
+	if !standardRange(code[0]) || code.Class() == ClassConfigFileError {
+		return OriginImplementationDefined
+	}
+	switch {
+	case code[2] == 'P':
+		return OriginPostgreSQL
+	case !standardRange(code[2]):
+		return OriginImplementationDefined
+	}
+	return OriginStandard
+}
+
+// IsGeneric reports whether a code is the generic code of its class, i.e.
+// its subclass is 000, e.g. IntegrityConstraintViolation (23000).
+func IsGeneric(code pq.ErrorCode) bool {
+	return validSQLState(string(code)) && code[2:] == "000"
+}
+
+// standardRange reports whether a class or subclass beginning with a given
+// character is reserved for the SQL standard.
+func standardRange(c byte) bool {
+	return '0' <= c && c <= '4' || 'A' <= c && c <= 'H'
+}
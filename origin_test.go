@@ -0,0 +1,48 @@
+package pqerror
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestOriginOf(t *testing.T) {
+	tests := []struct {
+		code pq.ErrorCode
+		want Origin
+	}{
+		{SuccessfulCompletion, OriginStandard},
+		{DivisionByZero, OriginStandard},
+		{StringDataRightTruncation, OriginStandard},
+		{IntegrityConstraintViolation, OriginStandard},
+		{RestrictViolation, OriginStandard},
+		{SerializationFailure, OriginStandard},
+		{FdwError, OriginStandard},
+		{FdwInvalidColumnName, OriginStandard},
+
+		{InvalidTextRepresentation, OriginPostgreSQL},
+		{DeadlockDetected, OriginPostgreSQL},
+		{UndefinedTable, OriginPostgreSQL},
+		{ExclusionViolation, OriginPostgreSQL},
+		{SyntaxError, OriginImplementationDefined},
+		{NotNullViolation, OriginImplementationDefined},
+		{ForeignKeyViolation, OriginImplementationDefined},
+		{UniqueViolation, OriginImplementationDefined},
+		{CheckViolation, OriginImplementationDefined},
+		{RaiseException, OriginImplementationDefined},
+		{InternalError, OriginImplementationDefined},
+		{ConfigFileError, OriginImplementationDefined},
+		{QueryCanceled, OriginImplementationDefined},
+		{"U0001", OriginImplementationDefined},
+
+		{"", OriginUnknown},
+		{"2350", OriginUnknown},
+		{"2350a", OriginUnknown},
+		{"235055", OriginUnknown},
+	}
+	for _, tt := range tests {
+		if got := OriginOf(tt.code); got != tt.want {
+			t.Errorf("OriginOf(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
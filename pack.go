@@ -0,0 +1,48 @@
+package pqerror
+
+import "github.com/lib/pq"
+
+// InvalidPacked is returned by Pack for strings that are not valid SQLSTATEs.
+// It is not the packed form of any code.
+const InvalidPacked = ^uint32(0)
+
+// ValidCode reports whether a code is a syntactically valid SQLSTATE, i.e.
+// five digits or upper-case ASCII letters. The code need not be known.
+func ValidCode(code pq.ErrorCode) bool {
+	return validSQLState(string(code))
+}
+
+// Pack packs a code into an integer the way the server does with
+// MAKE_SQLSTATE: each character takes six bits, the first character the
+// lowest ones. It returns InvalidPacked if the code is not valid, see
+// ValidCode.
+//
+// Packed codes fit in 30 bits and are unique, but note that their order is
+// not the order of the codes, since the first character is the least
+// significant.
+func Pack(code pq.ErrorCode) uint32 {
+	if !ValidCode(code) {
+		return InvalidPacked
+	}
+	var n uint32
+	for i := 0; i < 5; i++ {
+		n |= uint32(code[i]-'0') & 0x3F << (6 * uint(i))
+	}
+	return n
+}
+
+// Unpack returns the code packed by Pack, or an empty code if the integer is
+// not a packed code.
+func Unpack(n uint32) pq.ErrorCode {
+	if n>>30 != 0 {
+		return ""
+	}
+	var b [5]byte
+	for i := range b {
+		b[i] = byte(n>>(6*uint(i))&0x3F) + '0'
+	}
+	if !validSQLState(string(b[:])) {
+		return ""
+	}
+	return pq.ErrorCode(b[:])
+}
@@ -0,0 +1,289 @@
+package pqerror
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+)
+
+// ParseText parses the first error report found in a text, as printed by psql
+// or written to a log, back into a *pq.Error, e.g.
+//
+//	ERROR:  23505: duplicate key value violates unique constraint "users_pkey"
+//	DETAIL:  Key (id)=(1) already exists.
+//	LOCATION:  _bt_check_unique, nbtinsert.c:570
+//
+// Output of any psql VERBOSITY level is accepted, as are lines carrying a log
+// prefix and messages formatted by pq.Error.Error(). Fields missing from the
+// text are left empty. In particular the code is only known when the text was
+// produced with VERBOSITY set to verbose or sqlstate, and the position is only
+// known when it can be recovered from the "LINE n:" cursor display.
+func ParseText(text string) (*pq.Error, error) {
+	errs, err := parseText(text, 1)
+	if err != nil {
+		return nil, err
+	}
+	return errs[0], nil
+}
+
+// ParseTextAll parses all error reports found in a text. See ParseText.
+func ParseTextAll(text string) ([]*pq.Error, error) {
+	return parseText(text, -1)
+}
+
+var errNoReport = errors.New("pqerror: no error report found in text")
+
+var (
+	reportRe = regexp.MustCompile(`^(.*?)\b(ERROR|FATAL|PANIC|WARNING|NOTICE|INFO|LOG|DEBUG[1-5]?):\s+(.*)$`)
+	fieldRe  = regexp.MustCompile(`^(.*?)\b(DETAIL|HINT|QUERY|CONTEXT|STATEMENT|LOCATION|SCHEMA NAME|TABLE NAME|COLUMN NAME|DATATYPE NAME|CONSTRAINT NAME):\s+(.*)$`)
+	lineRe   = regexp.MustCompile(`^LINE (\d+): (.*)$`)
+	caretRe  = regexp.MustCompile(`^( *)\^\s*$`)
+	pqRe     = regexp.MustCompile(`^(.*?)\bpq: (.*)$`)
+	codeRe   = regexp.MustCompile(`^([0-9A-Z]{5})(?:: (.*))?$`)
+	charRe   = regexp.MustCompile(`^(.*) at character (\d+)$`)
+	locRe    = regexp.MustCompile(`^(?:(.*), )?(.*):(\d+)$`)
+)
+
+// isReportedCode reports whether a five-character message prefix is a
+// SQLSTATE rather than a word, e.g. "ABORT" in "ERROR:  ABORT: something".
+// It accepts catalog and registered codes and otherwise any prefix with a
+// digit, as every PostgreSQL SQLSTATE has.
+func isReportedCode(code pq.ErrorCode) bool {
+	if _, ok := Lookup(code); ok {
+		return true
+	}
+	if _, ok := LookupCustom(code); ok {
+		return true
+	}
+	return strings.ContainsAny(string(code), "0123456789")
+}
+
+// textReport is an error report being parsed.
+type textReport struct {
+	err *pq.Error
+	// prefix is the log prefix the report line carried, if any, in which
+	// case the field lines are expected to carry a prefix too.
+	prefix string
+	// last points to the field continuation lines are appended to.
+	last *string
+
+	// The "LINE n:" cursor display, if any.
+	line      int
+	lineText  string
+	caret     int
+	truncated bool
+}
+
+func parseText(text string, max int) ([]*pq.Error, error) {
+	var (
+		errs []*pq.Error
+		rep  *textReport
+	)
+	flush := func() bool {
+		if rep != nil {
+			rep.finish()
+			errs = append(errs, rep.err)
+			rep = nil
+		}
+		return max > 0 && len(errs) >= max
+	}
+	for _, line := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		// While a report is open, field lines take precedence, as their
+		// values may contain a severity, e.g. "DETAIL:  Failing row
+		// contains (1, ERROR: disk full)."
+		if rep != nil {
+			if m := fieldRe.FindStringSubmatch(line); m != nil && (m[1] == "" || rep.prefix != "") {
+				rep.field(m[2], m[3])
+				continue
+			}
+		}
+		if m := reportRe.FindStringSubmatch(line); m != nil && rep.startsReport(line, m[1]) {
+			if flush() {
+				return errs, nil
+			}
+			rep = newTextReport(m[1], m[2], m[3])
+			continue
+		}
+		if rep == nil {
+			if m := pqRe.FindStringSubmatch(line); m != nil {
+				rep = &textReport{err: &pq.Error{Message: m[2]}, caret: -1}
+				rep.last = &rep.err.Message
+			}
+			continue
+		}
+		if m := lineRe.FindStringSubmatch(line); m != nil {
+			rep.line, _ = strconv.Atoi(m[1])
+			rep.lineText = m[2]
+			rep.truncated = strings.HasPrefix(m[2], "...")
+			rep.last = nil
+			continue
+		}
+		if m := caretRe.FindStringSubmatch(line); m != nil && rep.line > 0 {
+			rep.caret = len(m[1])
+			continue
+		}
+		if line == "" {
+			rep.last = nil
+			continue
+		}
+		if rep.last != nil {
+			*rep.last += "\n" + strings.TrimPrefix(line, "\t")
+		}
+	}
+	flush()
+	if len(errs) == 0 {
+		return nil, errNoReport
+	}
+	return errs, nil
+}
+
+// startsReport reports whether a line matching reportRe with a given prefix
+// starts a new report rather than continuing the current one. Besides
+// unprefixed lines and lines carrying the prefix of the current report, only
+// lines of a log, whose prefix varies from entry to entry, start a report,
+// unless they are indented continuation lines.
+func (rep *textReport) startsReport(line, prefix string) bool {
+	switch {
+	case rep == nil, prefix == "", prefix == rep.prefix:
+		return true
+	case rep.prefix != "":
+		return !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t")
+	}
+	return false
+}
+
+func newTextReport(prefix, severity, msg string) *textReport {
+	rep := &textReport{
+		err:    &pq.Error{Severity: severity},
+		prefix: prefix,
+		caret:  -1,
+	}
+	if m := codeRe.FindStringSubmatch(msg); m != nil && isReportedCode(pq.ErrorCode(m[1])) {
+		rep.err.Code = pq.ErrorCode(m[1])
+		msg = m[2]
+	}
+	if m := charRe.FindStringSubmatch(msg); m != nil {
+		msg = m[1]
+		rep.err.Position = m[2]
+	}
+	rep.err.Message = msg
+	rep.last = &rep.err.Message
+	return rep
+}
+
+func (rep *textReport) field(label, value string) {
+	var f *string
+	switch label {
+	case "DETAIL":
+		f = &rep.err.Detail
+	case "HINT":
+		f = &rep.err.Hint
+	case "QUERY":
+		f = &rep.err.InternalQuery
+	case "CONTEXT":
+		f = &rep.err.Where
+	case "SCHEMA NAME":
+		f = &rep.err.Schema
+	case "TABLE NAME":
+		f = &rep.err.Table
+	case "COLUMN NAME":
+		f = &rep.err.Column
+	case "DATATYPE NAME":
+		f = &rep.err.DataTypeName
+	case "CONSTRAINT NAME":
+		f = &rep.err.Constraint
+	case "LOCATION":
+		if m := locRe.FindStringSubmatch(value); m != nil {
+			rep.err.Routine, rep.err.File, rep.err.Line = m[1], m[2], m[3]
+		}
+	}
+	if f != nil {
+		*f = value
+	}
+	rep.last = f
+}
+
+// finish recovers the error position from the cursor display. The position
+// of a client query can only be recovered from its first line, as the text
+// does not contain the query itself, whereas the position of an internal
+// query is computed from the QUERY field.
+func (rep *textReport) finish() {
+	if rep.line == 0 || rep.caret < 0 || rep.err.Position != "" {
+		return
+	}
+	col := rep.caret - len(fmt.Sprintf("LINE %d: ", rep.line))
+	shown := rep.lineText
+	if rep.truncated {
+		col -= 3
+		shown = shown[3:]
+	}
+	if col < 0 {
+		return
+	}
+	// Convert the screen column into a character offset within the shown text.
+	n, w := 0, 0
+	for _, r := range shown {
+		if w >= col {
+			break
+		}
+		w += runeWidth(r)
+		n++
+	}
+	if w < col {
+		n += col - w
+	}
+
+	if rep.err.InternalQuery == "" {
+		if rep.line == 1 && !rep.truncated {
+			rep.err.Position = strconv.Itoa(n + 1)
+		}
+		return
+	}
+	start, text, ok := queryLine(rep.err.InternalQuery, rep.line)
+	if !ok {
+		return
+	}
+	if rep.truncated {
+		shown = strings.TrimSuffix(shown, "...")
+		i := strings.Index(strings.Replace(text, "\t", " ", -1), shown)
+		if i < 0 {
+			return
+		}
+		start += utf8.RuneCountInString(text[:i])
+	}
+	rep.err.InternalPosition = strconv.Itoa(start + n + 1)
+}
+
+// queryLine returns the n-th line of a query and the character offset it
+// starts at. Lines are counted the way psql counts them: each "\r", "\n" or
+// "\r\n" ends a line.
+func queryLine(query string, n int) (int, string, bool) {
+	start, chars, line := 0, 0, 1
+	var prev rune
+	for i, r := range query {
+		if r == '\n' && prev == '\r' {
+			start = i + 1
+			chars++
+			prev = r
+			continue
+		}
+		if r == '\r' || r == '\n' {
+			if line == n {
+				return chars - utf8.RuneCountInString(query[start:i]), query[start:i], true
+			}
+			line++
+			start = i + 1
+		}
+		chars++
+		prev = r
+	}
+	if line == n {
+		return chars - utf8.RuneCountInString(query[start:]), query[start:], true
+	}
+	return 0, "", false
+}
@@ -0,0 +1,184 @@
+package pqerror
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestParseText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want pq.Error
+	}{
+		{
+			name: "psql default",
+			text: "ERROR:  duplicate key value violates unique constraint \"users_email_key\"\n" +
+				"DETAIL:  Key (email)=(jane@example.com) already exists.\n",
+			want: pq.Error{
+				Severity: "ERROR",
+				Message:  `duplicate key value violates unique constraint "users_email_key"`,
+				Detail:   "Key (email)=(jane@example.com) already exists.",
+			},
+		},
+		{
+			name: "psql verbose",
+			text: "ERROR:  23505: duplicate key value violates unique constraint \"users_email_key\"\n" +
+				"DETAIL:  Key (email)=(jane@example.com) already exists.\n" +
+				"SCHEMA NAME:  public\n" +
+				"TABLE NAME:  users\n" +
+				"CONSTRAINT NAME:  users_email_key\n" +
+				"LOCATION:  _bt_check_unique, nbtinsert.c:666\n",
+			want: pq.Error{
+				Severity:   "ERROR",
+				Code:       "23505",
+				Message:    `duplicate key value violates unique constraint "users_email_key"`,
+				Detail:     "Key (email)=(jane@example.com) already exists.",
+				Schema:     "public",
+				Table:      "users",
+				Constraint: "users_email_key",
+				Routine:    "_bt_check_unique",
+				File:       "nbtinsert.c",
+				Line:       "666",
+			},
+		},
+		{
+			name: "psql sqlstate",
+			text: "ERROR:  23505\n",
+			want: pq.Error{Severity: "ERROR", Code: "23505"},
+		},
+		{
+			name: "word prefix",
+			text: "ERROR:  ABORT: something\n",
+			want: pq.Error{Severity: "ERROR", Message: "ABORT: something"},
+		},
+		{
+			name: "unknown sqlstate",
+			text: "ERROR:  P9Z99: custom failure\n",
+			want: pq.Error{Severity: "ERROR", Code: "P9Z99", Message: "custom failure"},
+		},
+		{
+			name: "psql file prefix",
+			text: "psql:migrate.sql:12: ERROR:  relation \"users\" does not exist\n" +
+				"LINE 1: SELECT * FROM users;\n" +
+				"                      ^\n",
+			want: pq.Error{
+				Severity: "ERROR",
+				Message:  `relation "users" does not exist`,
+				Position: "15",
+			},
+		},
+		{
+			name: "log prefix",
+			text: "2024-03-01 10:00:00.123 UTC [4242] app@shop ERROR:  division by zero\n" +
+				"2024-03-01 10:00:00.123 UTC [4242] app@shop HINT:  Check the divisor.\n" +
+				"2024-03-01 10:00:00.123 UTC [4242] app@shop STATEMENT:  SELECT 1/0\n",
+			want: pq.Error{
+				Severity: "ERROR",
+				Message:  "division by zero",
+				Hint:     "Check the divisor.",
+			},
+		},
+		{
+			name: "pq message",
+			text: "insert user: pq: duplicate key value violates unique constraint \"users_pkey\"\n",
+			want: pq.Error{Message: `duplicate key value violates unique constraint "users_pkey"`},
+		},
+		{
+			name: "wide characters before caret",
+			text: "ERROR:  syntax error at or near \"FORM\"\n" +
+				"LINE 1: SELECT '日本' FORM t\n" +
+				"                      ^\n",
+			want: pq.Error{
+				Severity: "ERROR",
+				Message:  `syntax error at or near "FORM"`,
+				Position: "13",
+			},
+		},
+		{
+			name: "severity in field value",
+			text: "ERROR:  new row for relation \"jobs\" violates check constraint \"jobs_state_check\"\n" +
+				"DETAIL:  Failing row contains (1, ERROR: disk full).\n",
+			want: pq.Error{
+				Severity: "ERROR",
+				Message:  `new row for relation "jobs" violates check constraint "jobs_state_check"`,
+				Detail:   "Failing row contains (1, ERROR: disk full).",
+			},
+		},
+		{
+			name: "severity in continuation line",
+			text: "ERROR:  invalid input syntax\n" +
+				"DETAIL:  first line\n" +
+				"see ERROR: below\n",
+			want: pq.Error{
+				Severity: "ERROR",
+				Message:  "invalid input syntax",
+				Detail:   "first line\nsee ERROR: below",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseText(tt.text)
+			if err != nil {
+				t.Fatalf("ParseText() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseText() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTextAll(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "severity in field value",
+			text: "ERROR:  new row violates check constraint \"jobs_state_check\"\n" +
+				"DETAIL:  Failing row contains (1, ERROR: disk full).\n",
+			want: []string{`new row violates check constraint "jobs_state_check"`},
+		},
+		{
+			name: "psql file prefixes",
+			text: "psql:migrate.sql:3: ERROR:  relation \"users\" already exists\n" +
+				"psql:migrate.sql:7: ERROR:  relation \"orders\" already exists\n",
+			want: []string{`relation "users" already exists`, `relation "orders" already exists`},
+		},
+		{
+			name: "log entries",
+			text: "2024-03-01 10:00:00.123 UTC [4242] ERROR:  division by zero\n" +
+				"2024-03-01 10:00:00.123 UTC [4242] STATEMENT:  SELECT 1/0\n" +
+				"2024-03-01 10:00:01.456 UTC [4243] ERROR:  deadlock detected\n" +
+				"2024-03-01 10:00:01.456 UTC [4243] DETAIL:  Process 4243 waits for ShareLock on transaction 7; blocked by process 4244.\n" +
+				"\tProcess 4244 waits for ShareLock on transaction 8; blocked by process 4243.\n",
+			want: []string{"division by zero", "deadlock detected"},
+		},
+		{
+			name: "unprefixed reports",
+			text: "ERROR:  division by zero\n" +
+				"ERROR:  current transaction is aborted, commands ignored until end of transaction block\n",
+			want: []string{"division by zero", "current transaction is aborted, commands ignored until end of transaction block"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := ParseTextAll(tt.text)
+			if err != nil {
+				t.Fatalf("ParseTextAll() error = %v", err)
+			}
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTextAll() messages = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package pqerror
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Pattern is a compiled pattern over codes, e.g. "23*", "40P01|40001",
+// "08*,57P0?" or "!01*".
+//
+// A pattern is a list of terms separated by "|" or ",". A term is a code in
+// which "?" stands for any single character and "*" for any sequence of
+// characters. A term prefixed with "!" excludes the codes it matches. A code
+// matches a pattern if it matches any of its terms that are not excluding,
+// or any term at all if there are only excluding terms, and none of its
+// excluding terms, e.g. "23*,!23505" matches the codes of class 23 but
+// UniqueViolation.
+type Pattern struct {
+	src     string
+	include []string
+	exclude []string
+}
+
+// CompilePattern parses a pattern. It returns an error if the pattern is
+// malformed or if any of its terms matches no code of the catalog, see Lookup.
+// Patterns are case-insensitive.
+func CompilePattern(pattern string) (*Pattern, error) {
+	p := &Pattern{src: pattern}
+	for _, term := range strings.FieldsFunc(pattern, func(r rune) bool { return r == '|' || r == ',' }) {
+		term = strings.ToUpper(strings.TrimSpace(term))
+		exclude := strings.HasPrefix(term, "!")
+		if exclude {
+			term = term[1:]
+		}
+		if err := checkPatternTerm(term); err != nil {
+			return nil, fmt.Errorf("pqerror: invalid pattern %q: %v", pattern, err)
+		}
+		if exclude {
+			p.exclude = append(p.exclude, term)
+		} else {
+			p.include = append(p.include, term)
+		}
+	}
+	if len(p.include) == 0 && len(p.exclude) == 0 {
+		return nil, fmt.Errorf("pqerror: invalid pattern %q: no terms", pattern)
+	}
+	return p, nil
+}
+
+// MustCompilePattern is like CompilePattern but panics if the pattern cannot
+// be parsed.
+func MustCompilePattern(pattern string) *Pattern {
+	p, err := CompilePattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source of the pattern.
+func (p *Pattern) String() string {
+	return p.src
+}
+
+// MatchCode reports whether a code matches the pattern.
+func (p *Pattern) MatchCode(code pq.ErrorCode) bool {
+	if len(p.include) > 0 && !matchAnyTerm(p.include, string(code)) {
+		return false
+	}
+	return !matchAnyTerm(p.exclude, string(code))
+}
+
+// Match reports whether there is a *pq.Error in the chain of an error whose
+// code matches the pattern.
+func (p *Pattern) Match(err error) bool {
+	pqerr, ok := asPqError(err)
+	return ok && p.MatchCode(pqerr.Code)
+}
+
+// CodeMatches returns a predicate matching the errors whose code matches
+// a pattern.
+func CodeMatches(p *Pattern) Predicate {
+	return pqPredicate(fmt.Sprintf("pattern(%q)", p.src), func(err *pq.Error) bool {
+		return p.MatchCode(err.Code)
+	})
+}
+
+func checkPatternTerm(term string) error {
+	if term == "" {
+		return fmt.Errorf("empty term")
+	}
+	n := 0
+	for i := 0; i < len(term); i++ {
+		switch c := term[i]; {
+		case c == '*':
+		case c == '?' || '0' <= c && c <= '9' || 'A' <= c && c <= 'Z':
+			n++
+		default:
+			return fmt.Errorf("unexpected character %q in term %s", c, term)
+		}
+	}
+	if n > 5 || n < 5 && !strings.Contains(term, "*") {
+		return fmt.Errorf("term %s does not match five characters", term)
+	}
+	for _, info := range Codes() {
+		if matchTerm(term, string(info.Code)) {
+			return nil
+		}
+	}
+	for _, c := range CustomCodes() {
+		if matchTerm(term, string(c.Code)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("term %s matches no known code", term)
+}
+
+func matchAnyTerm(terms []string, code string) bool {
+	for _, term := range terms {
+		if matchTerm(term, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTerm reports whether a code matches a term of a pattern.
+func matchTerm(term, code string) bool {
+	for len(term) > 0 {
+		switch term[0] {
+		case '*':
+			for i := len(code); i >= 0; i-- {
+				if matchTerm(term[1:], code[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(code) == 0 {
+				return false
+			}
+		default:
+			if len(code) == 0 || code[0] != term[0] {
+				return false
+			}
+		}
+		term, code = term[1:], code[1:]
+	}
+	return len(code) == 0
+}
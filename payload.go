@@ -0,0 +1,95 @@
+package pqerror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ErrNotRaised is returned by DecodeDetail and DecodeHint for errors that were
+// not raised by RAISE EXCEPTION with RaiseException or a registered code.
+var ErrNotRaised = errors.New("pqerror: not a raised exception")
+
+// RaisedError holds the fields of an error raised from PL/pgSQL by
+// RAISE EXCEPTION ... USING, e.g.
+//
+//	RAISE EXCEPTION USING
+//		ERRCODE = 'U0001',
+//		MESSAGE = 'insufficient funds',
+//		DETAIL = '{"field":"amount","limit":100}',
+//		COLUMN = 'amount',
+//		TABLE = 'accounts';
+type RaisedError struct {
+	Code       pq.ErrorCode
+	Message    string
+	Detail     string
+	Hint       string
+	Column     string
+	Constraint string
+	DataType   string
+	Table      string
+	Schema     string
+}
+
+// AsRaised returns the fields of an error raised from PL/pgSQL. It reports
+// false unless there is a *pq.Error in the chain of the error whose code is
+// RaiseException or a code registered by Register.
+func AsRaised(err error) (*RaisedError, bool) {
+	pqerr, ok := asPqError(err)
+	if !ok || !isRaisedCode(pqerr.Code) {
+		return nil, false
+	}
+	return &RaisedError{
+		Code:       pqerr.Code,
+		Message:    pqerr.Message,
+		Detail:     pqerr.Detail,
+		Hint:       pqerr.Hint,
+		Column:     pqerr.Column,
+		Constraint: pqerr.Constraint,
+		DataType:   pqerr.DataTypeName,
+		Table:      pqerr.Table,
+		Schema:     pqerr.Schema,
+	}, true
+}
+
+// DecodeDetail decodes the JSON detail of an error raised from PL/pgSQL into
+// the value pointed to by v, see json.Unmarshal. It returns ErrNotRaised if
+// AsRaised reports false.
+func DecodeDetail(err error, v interface{}) error {
+	r, ok := AsRaised(err)
+	if !ok {
+		return ErrNotRaised
+	}
+	return decodePayload("detail", r.Detail, v)
+}
+
+// DecodeHint decodes the JSON hint of an error raised from PL/pgSQL into the
+// value pointed to by v, see json.Unmarshal. It returns ErrNotRaised if
+// AsRaised reports false.
+func DecodeHint(err error, v interface{}) error {
+	r, ok := AsRaised(err)
+	if !ok {
+		return ErrNotRaised
+	}
+	return decodePayload("hint", r.Hint, v)
+}
+
+func decodePayload(field, payload string, v interface{}) error {
+	if payload == "" {
+		return fmt.Errorf("pqerror: empty %s", field)
+	}
+	if err := json.Unmarshal([]byte(payload), v); err != nil {
+		return fmt.Errorf("pqerror: decoding %s: %w", field, err)
+	}
+	return nil
+}
+
+func isRaisedCode(code pq.ErrorCode) bool {
+	if code == RaiseException {
+		return true
+	}
+	_, ok := LookupCustom(code)
+	return ok
+}
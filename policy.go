@@ -0,0 +1,316 @@
+package pqerror
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Decision is the action a Policy prescribes for an error.
+type Decision struct {
+	// Retry reports whether the failed operation should be retried.
+	Retry bool `json:"retry,omitempty"`
+	// MaxRetries is the maximum number of retries. It must be positive if
+	// Retry is set, so that no policy retries forever.
+	MaxRetries int `json:"max_retries,omitempty"`
+	// HTTPStatus is the HTTP status code to respond with, or 0.
+	HTTPStatus int `json:"http_status,omitempty"`
+	// GRPCCode is the name of the gRPC status code to respond with, e.g.
+	// "Aborted", or an empty string.
+	GRPCCode string `json:"grpc_code,omitempty"`
+	// LogLevel is the level to log the error at, one of "debug", "info",
+	// "warn" and "error", or an empty string.
+	LogLevel string `json:"log_level,omitempty"`
+	// Alert reports whether the error should raise an alert.
+	Alert bool `json:"alert,omitempty"`
+	// MessageKey is the key of the message to show to users, e.g.
+	// "errors.email_taken".
+	MessageKey string `json:"message_key,omitempty"`
+
+	// Rule describes the selector of the rule the decision was made by, e.g.
+	// "code 55P03", or "default". It is set by Decide.
+	Rule string `json:"-"`
+}
+
+// Policy maps errors to decisions. Policies are usually loaded from JSON
+// files, e.g.
+//
+//	{
+//		"rules": [
+//			{
+//				"codes": ["lock_not_available", "40P01"],
+//				"action": {"retry": true, "max_retries": 3, "log_level": "warn"}
+//			},
+//			{
+//				"constraints": ["users_email_key"],
+//				"action": {"http_status": 409, "message_key": "errors.email_taken"}
+//			},
+//			{
+//				"patterns": ["08*,57P0?"],
+//				"classes": ["53"],
+//				"action": {"http_status": 503, "grpc_code": "Unavailable", "alert": true}
+//			}
+//		],
+//		"default": {"http_status": 500, "log_level": "error"}
+//	}
+//
+// A rule selects errors by codes, given as SQLSTATEs or condition names, by
+// classes, by patterns, see CompilePattern, and by constraint names. The most
+// specific selector wins: a constraint beats a code, which beats a pattern,
+// which beats a class, which beats the default.
+type Policy struct {
+	constraints map[string]Decision
+	codes       map[pq.ErrorCode]Decision
+	patterns    []patternDecision
+	classes     map[pq.ErrorClass]Decision
+	fallback    Decision
+}
+
+type patternDecision struct {
+	pattern  *Pattern
+	decision Decision
+}
+
+type policyFile struct {
+	Rules   []policyRule `json:"rules"`
+	Default Decision     `json:"default"`
+}
+
+type policyRule struct {
+	Codes       []string `json:"codes"`
+	Classes     []string `json:"classes"`
+	Patterns    []string `json:"patterns"`
+	Constraints []string `json:"constraints"`
+	Action      Decision `json:"action"`
+}
+
+// ParsePolicy parses a JSON policy. It returns an error if the policy refers
+// to unknown codes or classes, if an action is invalid, or if rules overlap,
+// i.e. if a code, class or constraint is selected by several rules, or if
+// several patterns match the same code.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var f policyFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("pqerror: policy: %v", err)
+	}
+	p := &Policy{
+		constraints: make(map[string]Decision),
+		codes:       make(map[pq.ErrorCode]Decision),
+		classes:     make(map[pq.ErrorClass]Decision),
+	}
+	if err := checkDecision(f.Default); err != nil {
+		return nil, fmt.Errorf("pqerror: policy: default: %v", err)
+	}
+	p.fallback = f.Default
+	for i, r := range f.Rules {
+		if err := p.addRule(r); err != nil {
+			return nil, fmt.Errorf("pqerror: policy: rule %d: %v", i+1, err)
+		}
+	}
+	if err := p.checkPatterns(); err != nil {
+		return nil, fmt.Errorf("pqerror: policy: %v", err)
+	}
+	return p, nil
+}
+
+// LoadPolicyFile reads and parses a JSON policy file, see ParsePolicy.
+func LoadPolicyFile(name string) (*Policy, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePolicy(data)
+}
+
+// Decide returns the decision of the most specific rule selecting an error,
+// or the default decision if no rule does, including for errors that are not
+// PostgreSQL errors. It returns the zero Decision for a nil error.
+//
+// The errors of a registered code get the HTTPStatus of their registration,
+// see CustomCode, unless the decision of a rule sets one: the registration is
+// more specific than the default decision but less than a rule.
+func (p *Policy) Decide(err error) Decision {
+	if err == nil {
+		return Decision{}
+	}
+	d, rule := p.lookup(err)
+	d.Rule = rule
+	if d.HTTPStatus == 0 || rule == "default" {
+		if pqerr, ok := asPqError(err); ok {
+			if c, ok := LookupCustom(pqerr.Code); ok && c.HTTPStatus != 0 {
+				d.HTTPStatus = c.HTTPStatus
+			}
+		}
+	}
+	return d
+}
+
+func (p *Policy) lookup(err error) (Decision, string) {
+	pqerr, ok := asPqError(err)
+	if !ok {
+		return p.fallback, "default"
+	}
+	if d, ok := p.constraints[pqerr.Constraint]; ok && pqerr.Constraint != "" {
+		return d, "constraint " + pqerr.Constraint
+	}
+	if d, ok := p.codes[pqerr.Code]; ok {
+		return d, "code " + string(pqerr.Code)
+	}
+	for _, pd := range p.patterns {
+		if pd.pattern.MatchCode(pqerr.Code) {
+			return pd.decision, "pattern " + pd.pattern.String()
+		}
+	}
+	if len(pqerr.Code) == 5 {
+		if d, ok := p.classes[pqerr.Code.Class()]; ok {
+			return d, "class " + string(pqerr.Code.Class())
+		}
+	}
+	return p.fallback, "default"
+}
+
+func (p *Policy) addRule(r policyRule) error {
+	if len(r.Codes)+len(r.Classes)+len(r.Patterns)+len(r.Constraints) == 0 {
+		return fmt.Errorf("no selectors")
+	}
+	if err := checkDecision(r.Action); err != nil {
+		return err
+	}
+	for _, name := range r.Constraints {
+		if name == "" {
+			return fmt.Errorf("empty constraint name")
+		}
+		if _, ok := p.constraints[name]; ok {
+			return fmt.Errorf("constraint %s selected by several rules", name)
+		}
+		p.constraints[name] = r.Action
+	}
+	for _, s := range r.Codes {
+		codes, err := parsePolicyCode(s)
+		if err != nil {
+			return err
+		}
+		for _, code := range codes {
+			if _, ok := p.codes[code]; ok {
+				return fmt.Errorf("code %s selected by several rules", code)
+			}
+			p.codes[code] = r.Action
+		}
+	}
+	for _, s := range r.Classes {
+		class := pq.ErrorClass(strings.ToUpper(s))
+		if !knownClass(class) {
+			return fmt.Errorf("unknown class %q", s)
+		}
+		if _, ok := p.classes[class]; ok {
+			return fmt.Errorf("class %s selected by several rules", class)
+		}
+		p.classes[class] = r.Action
+	}
+	for _, s := range r.Patterns {
+		pattern, err := CompilePattern(s)
+		if err != nil {
+			return fmt.Errorf("%s", strings.TrimPrefix(err.Error(), "pqerror: "))
+		}
+		p.patterns = append(p.patterns, patternDecision{pattern, r.Action})
+	}
+	return nil
+}
+
+// checkPatterns returns an error if several patterns match the same known
+// code.
+func (p *Policy) checkPatterns() error {
+	if len(p.patterns) < 2 {
+		return nil
+	}
+	var codes []pq.ErrorCode
+	for _, info := range Codes() {
+		codes = append(codes, info.Code)
+	}
+	for _, c := range CustomCodes() {
+		codes = append(codes, c.Code)
+	}
+	for _, code := range codes {
+		var first *Pattern
+		for _, pd := range p.patterns {
+			if !pd.pattern.MatchCode(code) {
+				continue
+			}
+			if first != nil {
+				return fmt.Errorf("patterns %q and %q overlap on code %s", first, pd.pattern, code)
+			}
+			first = pd.pattern
+		}
+	}
+	return nil
+}
+
+// parsePolicyCode parses a code given as a SQLSTATE or a condition name.
+func parsePolicyCode(s string) ([]pq.ErrorCode, error) {
+	if _, ok := Lookup(pq.ErrorCode(strings.ToUpper(s))); ok {
+		return []pq.ErrorCode{pq.ErrorCode(strings.ToUpper(s))}, nil
+	}
+	codes, err := ParseConditionName(s)
+	if err != nil {
+		return nil, fmt.Errorf("unknown code %q", s)
+	}
+	return codes, nil
+}
+
+func knownClass(class pq.ErrorClass) bool {
+	if _, ok := LookupClass(class); ok {
+		return true
+	}
+	for _, c := range CustomCodes() {
+		if c.Code.Class() == class {
+			return true
+		}
+	}
+	return false
+}
+
+func checkDecision(d Decision) error {
+	switch {
+	case d.MaxRetries < 0:
+		return fmt.Errorf("negative max_retries")
+	case d.MaxRetries > 0 && !d.Retry:
+		return fmt.Errorf("max_retries without retry")
+	case d.Retry && d.MaxRetries == 0:
+		return fmt.Errorf("retry without max_retries")
+	case d.HTTPStatus != 0 && (d.HTTPStatus < 100 || d.HTTPStatus > 599):
+		return fmt.Errorf("invalid http_status %d", d.HTTPStatus)
+	case d.GRPCCode != "" && !containsString(grpcCodes, d.GRPCCode):
+		return fmt.Errorf("unknown grpc_code %q", d.GRPCCode)
+	case d.LogLevel != "" && !containsString(logLevels, d.LogLevel):
+		return fmt.Errorf("unknown log_level %q", d.LogLevel)
+	}
+	return nil
+}
+
+var grpcCodes = []string{
+	"OK",
+	"Canceled",
+	"Unknown",
+	"InvalidArgument",
+	"DeadlineExceeded",
+	"NotFound",
+	"AlreadyExists",
+	"PermissionDenied",
+	"ResourceExhausted",
+	"FailedPrecondition",
+	"Aborted",
+	"OutOfRange",
+	"Unimplemented",
+	"Internal",
+	"Unavailable",
+	"DataLoss",
+	"Unauthenticated",
+}
+
+var logLevels = []string{"debug", "info", "warn", "error"}
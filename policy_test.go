@@ -0,0 +1,181 @@
+package pqerror
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+const testPolicy = `{
+	"rules": [
+		{
+			"codes": ["lock_not_available", "40P01"],
+			"action": {"retry": true, "max_retries": 3, "log_level": "warn"}
+		},
+		{
+			"constraints": ["users_email_key"],
+			"action": {"http_status": 409, "message_key": "errors.email_taken"}
+		},
+		{
+			"patterns": ["08*,57P0?"],
+			"classes": ["53"],
+			"action": {"http_status": 503, "grpc_code": "Unavailable", "alert": true}
+		},
+		{
+			"classes": ["23"],
+			"action": {"http_status": 422}
+		}
+	],
+	"default": {"http_status": 500, "log_level": "error"}
+}`
+
+func TestPolicyDecide(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+	tests := []struct {
+		err        error
+		rule       string
+		httpStatus int
+	}{
+		{&pq.Error{Code: UniqueViolation, Constraint: "users_email_key"}, "constraint users_email_key", 409},
+		{&pq.Error{Code: UniqueViolation, Constraint: "users_pkey"}, "class 23", 422},
+		{&pq.Error{Code: LockNotAvailable}, "code 55P03", 0},
+		{fmt.Errorf("update: %w", &pq.Error{Code: DeadlockDetected}), "code 40P01", 0},
+		{&pq.Error{Code: AdminShutdown}, "pattern 08*,57P0?", 503},
+		{&pq.Error{Code: TooManyConnections}, "class 53", 503},
+		{&pq.Error{Code: DivisionByZero}, "default", 500},
+		{errors.New("boom"), "default", 500},
+	}
+	for _, tt := range tests {
+		d := p.Decide(tt.err)
+		if d.Rule != tt.rule || d.HTTPStatus != tt.httpStatus {
+			t.Errorf("Decide(%v) = rule %q, http_status %d, want rule %q, http_status %d", tt.err, d.Rule, d.HTTPStatus, tt.rule, tt.httpStatus)
+		}
+	}
+	if d := p.Decide(nil); d != (Decision{}) {
+		t.Errorf("Decide(nil) = %+v, want zero", d)
+	}
+}
+
+func TestPolicyDecideRegisteredHTTPStatus(t *testing.T) {
+	defer unregister("U0001", "U0002", "U0003")
+	MustRegister(CustomCode{Code: "U0001", Name: "insufficient_funds", HTTPStatus: 402})
+	MustRegister(CustomCode{Code: "U0002", HTTPStatus: 423})
+	p, err := ParsePolicy([]byte(`{
+		"rules": [
+			{"codes": ["insufficient_funds"], "action": {"log_level": "info"}},
+			{"codes": ["U0002"], "action": {"http_status": 409}}
+		],
+		"default": {"http_status": 500}
+	}`))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+	tests := []struct {
+		code       pq.ErrorCode
+		httpStatus int
+	}{
+		{"U0001", 402},
+		{"U0002", 409},
+		{"U0003", 500},
+	}
+	for _, tt := range tests {
+		if d := p.Decide(&pq.Error{Code: tt.code}); d.HTTPStatus != tt.httpStatus {
+			t.Errorf("Decide(%s) http_status = %d, want %d", tt.code, d.HTTPStatus, tt.httpStatus)
+		}
+	}
+
+	// The registration beats the default decision.
+	MustRegister(CustomCode{Code: "U0003", HTTPStatus: 429})
+	if d := p.Decide(&pq.Error{Code: "U0003"}); d.HTTPStatus != 429 || d.Rule != "default" {
+		t.Errorf("Decide(U0003) = rule %q, http_status %d, want default, 429", d.Rule, d.HTTPStatus)
+	}
+}
+
+func TestParsePolicyErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{
+			name:   "duplicate code",
+			policy: `{"rules": [{"codes": ["40P01"], "action": {}}, {"codes": ["deadlock_detected"], "action": {}}]}`,
+			err:    "rule 2: code 40P01 selected by several rules",
+		},
+		{
+			name:   "duplicate code of condition",
+			policy: `{"rules": [{"codes": ["22001"], "action": {}}, {"codes": ["string_data_right_truncation"], "action": {}}]}`,
+			err:    "rule 2: code 22001 selected by several rules",
+		},
+		{
+			name:   "duplicate class",
+			policy: `{"rules": [{"classes": ["53"], "action": {}}, {"classes": ["53"], "action": {}}]}`,
+			err:    "rule 2: class 53 selected by several rules",
+		},
+		{
+			name:   "duplicate constraint",
+			policy: `{"rules": [{"constraints": ["users_pkey"], "action": {}}, {"constraints": ["users_pkey"], "action": {}}]}`,
+			err:    "rule 2: constraint users_pkey selected by several rules",
+		},
+		{
+			name:   "overlapping patterns",
+			policy: `{"rules": [{"patterns": ["08*"], "action": {}}, {"patterns": ["0800?"], "action": {}}]}`,
+			err:    `patterns "08*" and "0800?" overlap on code 08000`,
+		},
+		{
+			name:   "overlapping patterns in one rule",
+			policy: `{"rules": [{"patterns": ["57P0?", "57P01"], "action": {}}]}`,
+			err:    `patterns "57P0?" and "57P01" overlap on code 57P01`,
+		},
+		{
+			name:   "retry without max_retries",
+			policy: `{"rules": [{"codes": ["40001"], "action": {"retry": true}}]}`,
+			err:    "rule 1: retry without max_retries",
+		},
+		{
+			name:   "max_retries without retry",
+			policy: `{"rules": [{"codes": ["40001"], "action": {"max_retries": 3}}]}`,
+			err:    "rule 1: max_retries without retry",
+		},
+		{
+			name:   "negative max_retries",
+			policy: `{"default": {"retry": true, "max_retries": -1}}`,
+			err:    "default: negative max_retries",
+		},
+		{
+			name:   "unknown code",
+			policy: `{"rules": [{"codes": ["no_such_condition"], "action": {}}]}`,
+			err:    `rule 1: unknown code "no_such_condition"`,
+		},
+		{
+			name:   "unknown class",
+			policy: `{"rules": [{"classes": ["Q9"], "action": {}}]}`,
+			err:    `rule 1: unknown class "Q9"`,
+		},
+		{
+			name:   "no selectors",
+			policy: `{"rules": [{"action": {}}]}`,
+			err:    "rule 1: no selectors",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePolicy([]byte(tt.policy))
+			if err == nil {
+				t.Fatalf("ParsePolicy() succeeded, want error %q", tt.err)
+			}
+			if want := "pqerror: policy: " + tt.err; err.Error() != want {
+				t.Errorf("ParsePolicy() error = %q, want %q", err, want)
+			}
+			if strings.Count(err.Error(), "pqerror:") != 1 {
+				t.Errorf("ParsePolicy() error = %q, want a single prefix", err)
+			}
+		})
+	}
+}
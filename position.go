@@ -0,0 +1,135 @@
+package pqerror
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RenderPosition returns the cursor display of the position of an error the
+// way psql shows it, e.g.
+//
+//	LINE 1: SELECT * FORM users
+//	                 ^
+//
+// If the error has a Position, it is rendered inside the query the error was
+// returned for. Otherwise, if it has an InternalPosition, it is rendered
+// inside its InternalQuery. Positions count characters, not bytes. Lines
+// longer than 60 columns are cut around the position. The result ends with
+// a newline, or is empty if there is no *pq.Error with a position in the
+// chain of the error or if the position is out of the query.
+func RenderPosition(query string, err error) string {
+	query, loc := errorPosition(query, err)
+	var b strings.Builder
+	writePosition(&b, query, loc)
+	return b.String()
+}
+
+// errorPosition returns the query and the 1-based position to show
+// a cursor at for an error, or 0 if there is none.
+func errorPosition(query string, err error) (string, int) {
+	pqerr, ok := asPqError(err)
+	if !ok {
+		return "", 0
+	}
+	if pqerr.Position != "" {
+		loc, _ := strconv.Atoi(pqerr.Position)
+		return query, loc
+	}
+	if pqerr.InternalPosition != "" && pqerr.InternalQuery != "" {
+		loc, _ := strconv.Atoi(pqerr.InternalPosition)
+		return pqerr.InternalQuery, loc
+	}
+	return "", 0
+}
+
+const (
+	positionDisplaySize = 60
+	positionMinRightCut = 10
+)
+
+// writePosition writes the cursor display of a 1-based character position
+// inside a query. It is a port of reportErrorPosition of libpq.
+func writePosition(b *strings.Builder, query string, loc int) {
+	// Convert loc from 1-based to 0-based.
+	loc--
+	if loc < 0 {
+		return
+	}
+
+	// For each character up to the end of the line containing loc, qidx holds
+	// its byte offset and scridx its starting screen column.
+	var (
+		qidx    []int
+		scridx  []int
+		scroff  int
+		locLine = 1
+		ibeg    = 0
+		iend    = -1
+		cno     = 0
+		prev    rune
+	)
+	for i, r := range query {
+		qidx = append(qidx, i)
+		scridx = append(scridx, scroff)
+		if r == '\r' || r == '\n' {
+			if cno < loc {
+				// Each "\r" or "\n" ends a line, except "\r\n" together.
+				if r == '\r' || cno == 0 || prev != '\r' {
+					locLine++
+				}
+				ibeg = cno + 1
+			} else {
+				iend = cno
+				break
+			}
+		}
+		scroff += runeWidth(r)
+		prev = r
+		cno++
+	}
+	if iend < 0 {
+		iend = cno
+		qidx = append(qidx, len(query))
+		scridx = append(scridx, scroff)
+	}
+	if loc > cno {
+		return
+	}
+
+	// If the line is too long, truncate it.
+	begTrunc, endTrunc := false, false
+	if scridx[iend]-scridx[ibeg] > positionDisplaySize {
+		// Truncate the right side if that is enough.
+		if scridx[ibeg]+positionDisplaySize >= scridx[loc]+positionMinRightCut {
+			for scridx[iend]-scridx[ibeg] > positionDisplaySize {
+				iend--
+			}
+			endTrunc = true
+		} else {
+			// Truncate the right side if not too close to loc.
+			for scridx[loc]+positionMinRightCut < scridx[iend] {
+				iend--
+				endTrunc = true
+			}
+			// Truncate the left side if still too long.
+			for scridx[iend]-scridx[ibeg] > positionDisplaySize {
+				ibeg++
+				begTrunc = true
+			}
+		}
+	}
+
+	prefix := fmt.Sprintf("LINE %d: ", locLine)
+	if begTrunc {
+		prefix += "..."
+	}
+	b.WriteString(prefix)
+	b.WriteString(strings.Replace(query[qidx[ibeg]:qidx[iend]], "\t", " ", -1))
+	if endTrunc {
+		b.WriteString("...")
+	}
+	b.WriteByte('\n')
+	b.WriteString(strings.Repeat(" ", stringWidth(prefix)+scridx[loc]-scridx[ibeg]))
+	b.WriteString("^\n")
+}
@@ -0,0 +1,161 @@
+package pqerror
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// The expected outputs are those of psql 16 connected with client_encoding
+// set to UTF8.
+func TestRenderPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		position int
+		want     string
+	}{
+		{
+			name:     "short line",
+			query:    "SELECT * FORM users",
+			position: 10,
+			want: "" +
+				"LINE 1: SELECT * FORM users\n" +
+				"                 ^\n",
+		},
+		{
+			name:     "tab",
+			query:    "SELECT\t* FORM users",
+			position: 10,
+			want: "" +
+				"LINE 1: SELECT * FORM users\n" +
+				"                 ^\n",
+		},
+		{
+			name:     "line 2",
+			query:    "SELECT id,\n       nam\nFROM users",
+			position: 19,
+			want: "" +
+				"LINE 2:        nam\n" +
+				"               ^\n",
+		},
+		{
+			name:     "CRLF line 2",
+			query:    "SELECT id,\r\n       nam\r\nFROM users",
+			position: 20,
+			want: "" +
+				"LINE 2:        nam\n" +
+				"               ^\n",
+		},
+		{
+			name:     "CRLF line 3",
+			query:    "SELECT id,\r\n       name\r\nFROM usrs",
+			position: 31,
+			want: "" +
+				"LINE 3: FROM usrs\n" +
+				"             ^\n",
+		},
+		{
+			name:     "CR line 3",
+			query:    "SELECT id,\r       name\rFROM usrs",
+			position: 29,
+			want: "" +
+				"LINE 3: FROM usrs\n" +
+				"             ^\n",
+		},
+		{
+			name:     "right truncation",
+			query:    "SELECT * FORM t WHERE x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 AND ",
+			position: 10,
+			want: "" +
+				"LINE 1: SELECT * FORM t WHERE x = 1 AND x = 1 AND x = 1 AND x = 1 AN...\n" +
+				"                 ^\n",
+		},
+		{
+			name:     "left truncation",
+			query:    "SELECT * FROM t WHERE x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 ORDER BY id LIMT 1",
+			position: 111,
+			want: "" +
+				"LINE 1: ...1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 ORDER BY id LIMT 1\n" +
+				"                                                                 ^\n",
+		},
+		{
+			name:     "both sides truncation",
+			query:    "SELECT * FROM t WHERE x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 AND zz = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1",
+			position: 73,
+			want: "" +
+				"LINE 1: ...x = 1 AND x = 1 AND x = 1 AND x = 1 AND x = 1 AND zz = 1 AND...\n" +
+				"                                                             ^\n",
+		},
+		{
+			name:     "CJK",
+			query:    "SELECT '日本語' FORM t",
+			position: 14,
+			want: "" +
+				"LINE 1: SELECT '日本語' FORM t\n" +
+				"                        ^\n",
+		},
+		{
+			// libpq gives zero-width characters a width of 1.
+			name:     "combining mark",
+			query:    "SELECT 'e\u0301' FORM t",
+			position: 13,
+			want: "" +
+				"LINE 1: SELECT 'e\u0301' FORM t\n" +
+				"                    ^\n",
+		},
+		{
+			name:     "end of query",
+			query:    "SELECT 1 +",
+			position: 11,
+			want: "" +
+				"LINE 1: SELECT 1 +\n" +
+				"                  ^\n",
+		},
+		{
+			name:     "end of query after newline",
+			query:    "SELECT 1 +\n",
+			position: 12,
+			want: "" +
+				"LINE 2: \n" +
+				"        ^\n",
+		},
+		{
+			name:     "out of range",
+			query:    "SELECT 1 +",
+			position: 12,
+			want:     "",
+		},
+		{
+			name:     "zero",
+			query:    "SELECT 1 +",
+			position: 0,
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &pq.Error{Position: fmt.Sprint(tt.position)}
+			if got := RenderPosition(tt.query, err); got != tt.want {
+				t.Errorf("RenderPosition() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderInternalPosition(t *testing.T) {
+	err := fmt.Errorf("call: %w", &pq.Error{
+		InternalQuery:    "SELECT balanse FROM accounts",
+		InternalPosition: "8",
+	})
+	want := "" +
+		"LINE 1: SELECT balanse FROM accounts\n" +
+		"               ^\n"
+	if got := RenderPosition("SELECT transfer(1, 2)", err); got != want {
+		t.Errorf("RenderPosition() =\n%s\nwant\n%s", got, want)
+	}
+	if got := RenderPosition("SELECT 1", &pq.Error{}); got != "" {
+		t.Errorf("RenderPosition() without position = %q, want empty", got)
+	}
+}
@@ -0,0 +1,272 @@
+package pqerror
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Predicate is a reusable condition an error can be matched against, e.g.
+//
+//	var DuplicateUser = Any(
+//		Constraint("users_email_key"),
+//		Constraint("users_username_key"),
+//	).Named("duplicate user")
+//
+// Predicates are built by the functions of this package and combined with
+// Any, All and Not. The zero Predicate matches no error.
+type Predicate struct {
+	name  string
+	match func(err error) bool
+}
+
+// Match reports whether an error satisfies the predicate. A nil error never
+// does. The predicates inspecting PostgreSQL errors look for a *pq.Error in
+// the chain of the error, see errors.As.
+func (p Predicate) Match(err error) bool {
+	return err != nil && p.match != nil && p.match(err)
+}
+
+// Named returns a copy of the predicate with a given name.
+func (p Predicate) Named(name string) Predicate {
+	p.name = name
+	return p
+}
+
+// String returns the name of the predicate. Unless set by Named, the name
+// describes the predicate, e.g. "any(code(23505), class(40))".
+func (p Predicate) String() string {
+	if p.name == "" {
+		return "none"
+	}
+	return p.name
+}
+
+// pqPredicate returns a predicate matching the PostgreSQL errors satisfying
+// a given function.
+func pqPredicate(name string, fn func(*pq.Error) bool) Predicate {
+	return Predicate{
+		name: name,
+		match: func(err error) bool {
+			pqerr, ok := asPqError(err)
+			return ok && fn(pqerr)
+		},
+	}
+}
+
+// Code returns a predicate matching the errors of any of the given codes.
+func Code(codes ...pq.ErrorCode) Predicate {
+	codes = append([]pq.ErrorCode(nil), codes...)
+	names := make([]string, len(codes))
+	for i, code := range codes {
+		names[i] = string(code)
+	}
+	return pqPredicate(describePredicate("code", names), func(err *pq.Error) bool {
+		for _, code := range codes {
+			if err.Code == code {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// When returns a predicate matching the errors an exception block of PL/pgSQL
+// catching any of the given codes would catch, see Catches.
+func When(codes ...pq.ErrorCode) Predicate {
+	codes = append([]pq.ErrorCode(nil), codes...)
+	names := make([]string, len(codes))
+	for i, code := range codes {
+		names[i] = string(code)
+	}
+	return pqPredicate(describePredicate("when", names), func(err *pq.Error) bool {
+		return catches(err.Code, codes)
+	})
+}
+
+// Class returns a predicate matching the errors of any of the given classes.
+func Class(classes ...pq.ErrorClass) Predicate {
+	classes = append([]pq.ErrorClass(nil), classes...)
+	names := make([]string, len(classes))
+	for i, class := range classes {
+		names[i] = string(class)
+	}
+	return pqPredicate(describePredicate("class", names), func(err *pq.Error) bool {
+		for _, class := range classes {
+			if len(err.Code) == 5 && err.Code.Class() == class {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Constraint returns a predicate matching the errors reported against any of
+// the given constraints.
+func Constraint(names ...string) Predicate {
+	names = append([]string(nil), names...)
+	return pqPredicate(describePredicate("constraint", names), func(err *pq.Error) bool {
+		return err.Constraint != "" && containsString(names, err.Constraint)
+	})
+}
+
+// Table returns a predicate matching the errors reported against any of the
+// given tables. A table name may be qualified by a schema name, e.g.
+// "public.orders", otherwise tables of any schema match.
+func Table(names ...string) Predicate {
+	names = append([]string(nil), names...)
+	return pqPredicate(describePredicate("table", names), func(err *pq.Error) bool {
+		if err.Table == "" {
+			return false
+		}
+		return containsString(names, err.Table) || containsString(names, err.Schema+"."+err.Table)
+	})
+}
+
+// SeverityIs returns a predicate matching the errors of any of the given
+// severities, see SeverityOf.
+func SeverityIs(severities ...Severity) Predicate {
+	severities = append([]Severity(nil), severities...)
+	names := make([]string, len(severities))
+	for i, s := range severities {
+		names[i] = s.String()
+	}
+	return pqPredicate(describePredicate("severity", names), func(err *pq.Error) bool {
+		sev := SeverityOf(err)
+		for _, s := range severities {
+			if sev == s {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// SeverityAtLeast returns a predicate matching the errors of a given or
+// higher severity, see SeverityOf.
+func SeverityAtLeast(severity Severity) Predicate {
+	return pqPredicate("severity(>="+severity.String()+")", func(err *pq.Error) bool {
+		return SeverityOf(err).AtLeast(severity)
+	})
+}
+
+// ConnFailureIs returns a predicate matching the connection failures of any
+// of the given kinds, see ConnFailureOf. Unlike most predicates, it matches
+// errors of the driver and the network as well.
+func ConnFailureIs(kinds ...ConnFailure) Predicate {
+	kinds = append([]ConnFailure(nil), kinds...)
+	names := make([]string, len(kinds))
+	for i, k := range kinds {
+		names[i] = k.String()
+	}
+	return Predicate{
+		name: describePredicate("conn_failure", names),
+		match: func(err error) bool {
+			f := ConnFailureOf(err)
+			for _, k := range kinds {
+				if f == k {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// CategoryIs returns a predicate matching the errors of any of the given
+// categories, see CategoryOf.
+func CategoryIs(categories ...Category) Predicate {
+	categories = append([]Category(nil), categories...)
+	names := make([]string, len(categories))
+	for i, c := range categories {
+		names[i] = c.String()
+	}
+	return Predicate{
+		name: describePredicate("category", names),
+		match: func(err error) bool {
+			c := CategoryOf(err)
+			for _, want := range categories {
+				if c == want {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// MessageMatches returns a predicate matching the errors whose primary
+// message matches a regular expression.
+func MessageMatches(re *regexp.Regexp) Predicate {
+	return pqPredicate(fmt.Sprintf("message(%q)", re), func(err *pq.Error) bool {
+		return re.MatchString(err.Message)
+	})
+}
+
+// Any returns a predicate matching the errors satisfying any of the given
+// predicates.
+func Any(predicates ...Predicate) Predicate {
+	predicates = append([]Predicate(nil), predicates...)
+	return Predicate{
+		name: describePredicates("any", predicates),
+		match: func(err error) bool {
+			for _, p := range predicates {
+				if p.Match(err) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// All returns a predicate matching the errors satisfying all of the given
+// predicates.
+func All(predicates ...Predicate) Predicate {
+	predicates = append([]Predicate(nil), predicates...)
+	return Predicate{
+		name: describePredicates("all", predicates),
+		match: func(err error) bool {
+			for _, p := range predicates {
+				if !p.Match(err) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+// Not returns a predicate matching the errors not satisfying a given
+// predicate.
+func Not(p Predicate) Predicate {
+	return Predicate{
+		name: "not(" + p.String() + ")",
+		match: func(err error) bool {
+			return !p.Match(err)
+		},
+	}
+}
+
+func describePredicate(kind string, args []string) string {
+	return kind + "(" + strings.Join(args, ", ") + ")"
+}
+
+func describePredicates(kind string, predicates []Predicate) string {
+	names := make([]string, len(predicates))
+	for i, p := range predicates {
+		names[i] = p.String()
+	}
+	return describePredicate(kind, names)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package pqerror
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestPredicateMatch(t *testing.T) {
+	dupEmail := &pq.Error{Code: UniqueViolation, Schema: "public", Table: "users", Constraint: "users_email_key"}
+	dupName := &pq.Error{Code: UniqueViolation, Schema: "public", Table: "users", Constraint: "users_username_key"}
+	fk := &pq.Error{Code: ForeignKeyViolation, Schema: "billing", Table: "orders", Constraint: "orders_user_id_fkey"}
+	deadlock := &pq.Error{Severity: "ERROR", Code: DeadlockDetected, Message: "deadlock detected"}
+	duplicateUser := Any(Constraint("users_email_key"), Constraint("users_username_key"))
+
+	tests := []struct {
+		name string
+		p    Predicate
+		err  error
+		want bool
+	}{
+		{"zero", Predicate{}, dupEmail, false},
+		{"nil error", Not(Code(UniqueViolation)), nil, false},
+		{"non-pq error", Code(UniqueViolation), errors.New("boom"), false},
+
+		{"code", Code(UniqueViolation), dupEmail, true},
+		{"code mismatch", Code(UniqueViolation, CheckViolation), fk, false},
+		{"when class", When(pq.ErrorCode(ClassIntegrityConstraintViolation + "000")), fk, true},
+		{"class", Class(ClassTransactionRollback), deadlock, true},
+		{"constraint", Constraint("users_email_key"), dupEmail, true},
+		{"table", Table("users"), dupEmail, true},
+		{"qualified table", Table("billing.orders"), fk, true},
+		{"qualified table mismatch", Table("public.orders"), fk, false},
+		{"severity", SeverityIs(SeverityError), deadlock, true},
+		{"message", MessageMatches(regexp.MustCompile(`^deadlock`)), deadlock, true},
+
+		{"wrapped", Code(UniqueViolation), fmt.Errorf("create user: %w", dupEmail), true},
+		{"wrapped twice", duplicateUser, fmt.Errorf("handler: %w", fmt.Errorf("create user: %w", dupName)), true},
+
+		{"any first", duplicateUser, dupEmail, true},
+		{"any second", duplicateUser, dupName, true},
+		{"any none", duplicateUser, fk, false},
+		{"any empty", Any(), dupEmail, false},
+		{"all", All(Code(UniqueViolation), Table("users")), dupName, true},
+		{"all one fails", All(Code(UniqueViolation), Table("orders")), dupName, false},
+		{"not", Not(duplicateUser), fk, true},
+		{"not matched", Not(duplicateUser), dupEmail, false},
+		{"nested", All(Class(ClassIntegrityConstraintViolation), Not(duplicateUser)), fk, true},
+		{"named", duplicateUser.Named("duplicate user"), dupName, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.Match(tt.err); got != tt.want {
+				t.Errorf("%v.Match(%v) = %v, want %v", tt.p, tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPredicateString(t *testing.T) {
+	duplicateUser := Any(Constraint("users_email_key"), Constraint("users_username_key"))
+	tests := []struct {
+		p    Predicate
+		want string
+	}{
+		{Predicate{}, "none"},
+		{Code(UniqueViolation, CheckViolation), "code(23505, 23514)"},
+		{Class(ClassTransactionRollback), "class(40)"},
+		{SeverityAtLeast(SeverityFatal), "severity(>=FATAL)"},
+		{duplicateUser, "any(constraint(users_email_key), constraint(users_username_key))"},
+		{All(Table("users"), Not(Code(UniqueViolation))), "all(table(users), not(code(23505)))"},
+		{duplicateUser.Named("duplicate user"), "duplicate user"},
+		{Not(duplicateUser.Named("duplicate user")), "not(duplicate user)"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestPredicateNamedCopies(t *testing.T) {
+	p := Code(UniqueViolation)
+	named := p.Named("unique")
+	if p.String() != "code(23505)" || named.String() != "unique" {
+		t.Errorf("Named() changed the original: %q, %q", p, named)
+	}
+}
@@ -0,0 +1,160 @@
+package pqerror
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+// CustomCode describes an application-defined code raised from PL/pgSQL,
+// e.g. by RAISE EXCEPTION ... USING ERRCODE = 'U0001'.
+type CustomCode struct {
+	// Code is the five-character SQLSTATE, e.g. "U0001".
+	Code pq.ErrorCode
+	// Name is the condition name, e.g. "insufficient_funds". It is optional.
+	Name string
+	// Description is a short human readable description of the code.
+	Description string
+	// Category is the category of the errors of the code, see CategoryOf.
+	Category Category
+	// HTTPStatus is the HTTP status code the errors of the code translate to,
+	// or 0 if unspecified. Policy.Decide falls back to it.
+	HTTPStatus int
+	// Retryable reports whether a transaction failing with the code may
+	// succeed when retried, in which case RunTx retries it.
+	Retryable bool
+}
+
+var registry = struct {
+	sync.RWMutex
+	codes map[pq.ErrorCode]CustomCode
+	names map[string]pq.ErrorCode
+}{
+	codes: make(map[pq.ErrorCode]CustomCode),
+	names: make(map[string]pq.ErrorCode),
+}
+
+// Register declares an application-defined code. Once registered, the code
+// takes part in Lookup, LookupCondition and CategoryOf alongside the codes of
+// PostgreSQL.
+//
+// Register returns an error if the code is not a valid SQLSTATE, if it
+// belongs to a class defined by PostgreSQL or reserved for the SQL standard,
+// i.e. beginning with 0-4 or A-H, if it is the generic code of its class,
+// e.g. U0000, or if the code or the name is already in use.
+func Register(c CustomCode) error {
+	if !validSQLState(string(c.Code)) {
+		return fmt.Errorf("pqerror: invalid code %q", c.Code)
+	}
+	if _, ok := LookupClass(c.Code.Class()); ok {
+		return fmt.Errorf("pqerror: code %s belongs to class %s reserved by PostgreSQL", c.Code, c.Code.Class())
+	}
+	if standardRange(c.Code[0]) {
+		return fmt.Errorf("pqerror: code %s belongs to class %s reserved for the SQL standard", c.Code, c.Code.Class())
+	}
+	if IsGeneric(c.Code) {
+		return fmt.Errorf("pqerror: code %s is the generic code of class %s", c.Code, c.Code.Class())
+	}
+	if c.Name != "" {
+		if !validConditionName(c.Name) {
+			return fmt.Errorf("pqerror: invalid condition name %q", c.Name)
+		}
+		if _, ok := conditionIndex[c.Name]; ok {
+			return fmt.Errorf("pqerror: condition name %s reserved by PostgreSQL", c.Name)
+		}
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.codes[c.Code]; ok {
+		return fmt.Errorf("pqerror: code %s already registered", c.Code)
+	}
+	if c.Name != "" {
+		if code, ok := registry.names[c.Name]; ok {
+			return fmt.Errorf("pqerror: condition name %s already registered for code %s", c.Name, code)
+		}
+		registry.names[c.Name] = c.Code
+	}
+	registry.codes[c.Code] = c
+	return nil
+}
+
+// MustRegister is like Register but panics if the code cannot be registered.
+// It returns the code, so it can be used to declare a package variable, e.g.
+//
+//	var InsufficientFunds = pqerror.MustRegister(pqerror.CustomCode{
+//		Code:       "U0001",
+//		Name:       "insufficient_funds",
+//		Category:   pqerror.CategoryConflict,
+//		HTTPStatus: http.StatusPaymentRequired,
+//	})
+func MustRegister(c CustomCode) pq.ErrorCode {
+	if err := Register(c); err != nil {
+		panic(err)
+	}
+	return c.Code
+}
+
+// LookupCustom returns the registration of an application-defined code.
+func LookupCustom(code pq.ErrorCode) (CustomCode, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	c, ok := registry.codes[code]
+	return c, ok
+}
+
+// CustomCodes returns all registered codes ordered by code.
+func CustomCodes() []CustomCode {
+	registry.RLock()
+	codes := make([]CustomCode, 0, len(registry.codes))
+	for _, c := range registry.codes {
+		codes = append(codes, c)
+	}
+	registry.RUnlock()
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
+
+// lookupCustomCondition returns the registered code of a condition name.
+func lookupCustomCondition(name string) (CustomCode, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	code, ok := registry.names[name]
+	if !ok {
+		return CustomCode{}, false
+	}
+	return registry.codes[code], true
+}
+
+func (c CustomCode) info() CodeInfo {
+	return CodeInfo{Code: c.Code, Condition: c.Name, Description: c.Description}
+}
+
+// validSQLState reports whether a string consists of five digits or
+// upper-case ASCII letters.
+func validSQLState(s string) bool {
+	if len(s) != 5 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !('0' <= c && c <= '9' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// validConditionName reports whether a string is a lower-case identifier.
+func validConditionName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || c == '_' || i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
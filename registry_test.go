@@ -0,0 +1,91 @@
+package pqerror
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// unregister removes codes registered by a test.
+func unregister(codes ...pq.ErrorCode) {
+	registry.Lock()
+	defer registry.Unlock()
+	for _, code := range codes {
+		if c, ok := registry.codes[code]; ok {
+			delete(registry.names, c.Name)
+			delete(registry.codes, code)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer unregister("U0001", "U0002", "Z9001")
+
+	if err := Register(CustomCode{Code: "U0001", Name: "insufficient_funds"}); err != nil {
+		t.Fatalf("Register(U0001) error = %v", err)
+	}
+	if err := Register(CustomCode{Code: "U0002"}); err != nil {
+		t.Fatalf("Register(U0002) error = %v", err)
+	}
+	if err := Register(CustomCode{Code: "Z9001", Name: "quota_exceeded"}); err != nil {
+		t.Fatalf("Register(Z9001) error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		code CustomCode
+	}{
+		{"invalid code", CustomCode{Code: "u0003"}},
+		{"short code", CustomCode{Code: "U003"}},
+		{"postgres class", CustomCode{Code: "P0100"}},
+		{"postgres class in implementation range", CustomCode{Code: "XX100"}},
+		{"standard class", CustomCode{Code: "2Z001"}},
+		{"standard class A-H", CustomCode{Code: "H1001"}},
+		{"generic code", CustomCode{Code: "U0000"}},
+		{"invalid name", CustomCode{Code: "U0003", Name: "Insufficient-Funds"}},
+		{"postgres name", CustomCode{Code: "U0003", Name: "unique_violation"}},
+		{"registered code", CustomCode{Code: "U0001", Name: "overdraft"}},
+		{"registered unnamed code", CustomCode{Code: "U0002"}},
+		{"registered name", CustomCode{Code: "U0003", Name: "insufficient_funds"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Register(tt.code); err == nil {
+				unregister(tt.code.Code)
+				t.Errorf("Register(%+v) succeeded, want error", tt.code)
+			}
+		})
+	}
+
+	// Failed registrations leave the registry untouched.
+	if c, ok := LookupCustom("U0001"); !ok || c.Name != "insufficient_funds" {
+		t.Errorf("LookupCustom(U0001) = %+v, %v", c, ok)
+	}
+	if _, ok := LookupCustom("U0003"); ok {
+		t.Errorf("LookupCustom(U0003) found a code")
+	}
+	if c, ok := lookupCustomCondition("insufficient_funds"); !ok || c.Code != "U0001" {
+		t.Errorf("lookupCustomCondition(insufficient_funds) = %+v, %v", c, ok)
+	}
+}
+
+func TestMustRegisterPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustRegister(2Z001) did not panic")
+		}
+	}()
+	MustRegister(CustomCode{Code: "2Z001"})
+}
+
+func TestRegisteredLookup(t *testing.T) {
+	defer unregister("U0001")
+	MustRegister(CustomCode{Code: "U0001", Name: "insufficient_funds", Description: "The account balance does not cover the payment."})
+	want := CodeInfo{Code: "U0001", Condition: "insufficient_funds", Description: "The account balance does not cover the payment."}
+	if got, ok := Lookup("U0001"); !ok || got != want {
+		t.Errorf("Lookup(U0001) = %+v, %v, want %+v", got, ok, want)
+	}
+	if got := LookupCondition("insufficient_funds"); len(got) != 1 || got[0] != want {
+		t.Errorf("LookupCondition(insufficient_funds) = %+v, want [%+v]", got, want)
+	}
+}
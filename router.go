@@ -0,0 +1,123 @@
+package pqerror
+
+import "github.com/lib/pq"
+
+// Handler handles an error. The pqerr argument is the *pq.Error found in the
+// chain of err, or nil if there is none.
+type Handler func(err error, pqerr *pq.Error) error
+
+// Router dispatches errors to handlers registered by constraint name, by code
+// and by class, e.g.
+//
+//	r := NewRouter().
+//		Constraint("users_email_key", emailTaken).
+//		Code(UniqueViolation, conflict).
+//		Class(ClassTransactionRollback, retry).
+//		Default(internal)
+//
+// The most specific registration wins: a constraint handler beats a code
+// handler, which beats a class handler, which beats the default handler.
+//
+// The zero Router is ready to use. A Router must not be modified concurrently
+// with Handle.
+type Router struct {
+	constraints map[string]Handler
+	codes       map[pq.ErrorCode]Handler
+	classes     map[pq.ErrorClass]Handler
+	fallback    Handler
+}
+
+// NewRouter returns an empty router.
+func NewRouter() *Router {
+	return new(Router)
+}
+
+// Constraint registers a handler of the errors reported against a given
+// constraint. It panics if a handler is already registered for the
+// constraint.
+func (r *Router) Constraint(name string, h Handler) *Router {
+	if r.constraints == nil {
+		r.constraints = make(map[string]Handler)
+	}
+	if _, ok := r.constraints[name]; ok {
+		panic("pqerror: multiple registrations for constraint " + name)
+	}
+	r.constraints[name] = mustHandler(h)
+	return r
+}
+
+// Code registers a handler of the errors of a given code. It panics if
+// a handler is already registered for the code.
+func (r *Router) Code(code pq.ErrorCode, h Handler) *Router {
+	if r.codes == nil {
+		r.codes = make(map[pq.ErrorCode]Handler)
+	}
+	if _, ok := r.codes[code]; ok {
+		panic("pqerror: multiple registrations for code " + string(code))
+	}
+	r.codes[code] = mustHandler(h)
+	return r
+}
+
+// Class registers a handler of the errors of a given class. It panics if
+// a handler is already registered for the class.
+func (r *Router) Class(class pq.ErrorClass, h Handler) *Router {
+	if r.classes == nil {
+		r.classes = make(map[pq.ErrorClass]Handler)
+	}
+	if _, ok := r.classes[class]; ok {
+		panic("pqerror: multiple registrations for class " + string(class))
+	}
+	r.classes[class] = mustHandler(h)
+	return r
+}
+
+// Default registers a handler of the errors no other handler is registered
+// for, including the errors that are not PostgreSQL errors. It panics if
+// a default handler is already registered.
+func (r *Router) Default(h Handler) *Router {
+	if r.fallback != nil {
+		panic("pqerror: multiple registrations for default handler")
+	}
+	r.fallback = mustHandler(h)
+	return r
+}
+
+// Handle passes an error to the most specific handler registered for it and
+// returns the result. A nil error is returned as is, as is an error no handler
+// is registered for.
+func (r *Router) Handle(err error) error {
+	if err == nil {
+		return nil
+	}
+	if h, pqerr := r.lookup(err); h != nil {
+		return h(err, pqerr)
+	}
+	return err
+}
+
+func (r *Router) lookup(err error) (Handler, *pq.Error) {
+	pqerr, ok := asPqError(err)
+	if !ok {
+		return r.fallback, nil
+	}
+	if h, ok := r.constraints[pqerr.Constraint]; ok && pqerr.Constraint != "" {
+		return h, pqerr
+	}
+	if h, ok := r.codes[pqerr.Code]; ok {
+		return h, pqerr
+	}
+	if len(pqerr.Code) == 5 {
+		if h, ok := r.classes[pqerr.Code.Class()]; ok {
+			return h, pqerr
+		}
+	}
+	return r.fallback, pqerr
+}
+
+func mustHandler(h Handler) Handler {
+	if h == nil {
+		panic("pqerror: nil handler")
+	}
+	return h
+}
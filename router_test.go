@@ -0,0 +1,106 @@
+package pqerror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// routedError records the handler an error was routed to.
+type routedError struct {
+	handler string
+	err     error
+	pqerr   *pq.Error
+}
+
+func (e *routedError) Error() string { return e.handler + ": " + e.err.Error() }
+
+func route(name string) Handler {
+	return func(err error, pqerr *pq.Error) error {
+		return &routedError{handler: name, err: err, pqerr: pqerr}
+	}
+}
+
+func TestRouterHandle(t *testing.T) {
+	r := NewRouter().
+		Constraint("users_email_key", route("constraint")).
+		Code(UniqueViolation, route("code")).
+		Class(ClassIntegrityConstraintViolation, route("class")).
+		Default(route("default"))
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"constraint", &pq.Error{Code: UniqueViolation, Constraint: "users_email_key"}, "constraint"},
+		{"constraint of another code", &pq.Error{Code: CheckViolation, Constraint: "users_email_key"}, "constraint"},
+		{"code", &pq.Error{Code: UniqueViolation, Constraint: "users_username_key"}, "code"},
+		{"code without constraint", &pq.Error{Code: UniqueViolation}, "code"},
+		{"class", &pq.Error{Code: ForeignKeyViolation, Constraint: "orders_user_id_fkey"}, "class"},
+		{"default", &pq.Error{Code: SerializationFailure}, "default"},
+		{"malformed code", &pq.Error{Code: "23"}, "default"},
+		{"wrapped", fmt.Errorf("create user: %w", &pq.Error{Code: UniqueViolation, Constraint: "users_email_key"}), "constraint"},
+		{"non-pq error", errors.New("boom"), "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := r.Handle(tt.err).(*routedError)
+			if !ok {
+				t.Fatalf("Handle() was not routed")
+			}
+			if got.handler != tt.want {
+				t.Errorf("Handle() routed to %s, want %s", got.handler, tt.want)
+			}
+			if got.err != tt.err {
+				t.Errorf("Handle() passed %v, want %v", got.err, tt.err)
+			}
+			if pqerr, _ := asPqError(tt.err); got.pqerr != pqerr {
+				t.Errorf("Handle() passed pqerr %v, want %v", got.pqerr, pqerr)
+			}
+		})
+	}
+}
+
+func TestRouterHandleNil(t *testing.T) {
+	r := NewRouter().Default(route("default"))
+	if err := r.Handle(nil); err != nil {
+		t.Errorf("Handle(nil) = %v, want nil", err)
+	}
+}
+
+func TestRouterWithoutDefault(t *testing.T) {
+	var r Router
+	r.Code(UniqueViolation, route("code"))
+	for _, err := range []error{errors.New("boom"), &pq.Error{Code: CheckViolation}} {
+		if got := r.Handle(err); got != err {
+			t.Errorf("Handle(%v) = %v, want the error as is", err, got)
+		}
+	}
+}
+
+func TestRouterDuplicateRegistration(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *Router)
+	}{
+		{"constraint", func(r *Router) { r.Constraint("users_email_key", route("x")) }},
+		{"code", func(r *Router) { r.Code(UniqueViolation, route("x")) }},
+		{"class", func(r *Router) { r.Class(ClassTransactionRollback, route("x")) }},
+		{"default", func(r *Router) { r.Default(route("x")) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter()
+			tt.register(r)
+			defer func() {
+				if recover() == nil {
+					t.Errorf("second registration did not panic")
+				}
+			}()
+			tt.register(r)
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package pqerror
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// LogFormat is a PostgreSQL server log format, see log_destination.
+type LogFormat int
+
+const (
+	LogStderr LogFormat = iota
+	LogCSV
+	LogJSON
+)
+
+// ParseLogFormat parses a log format given by its log_destination name,
+// i.e. "stderr", "csvlog" or "jsonlog".
+func ParseLogFormat(s string) (LogFormat, error) {
+	switch strings.ToLower(s) {
+	case "stderr":
+		return LogStderr, nil
+	case "csvlog", "csv":
+		return LogCSV, nil
+	case "jsonlog", "json":
+		return LogJSON, nil
+	}
+	return 0, fmt.Errorf("pqerror: unknown log format %q", s)
+}
+
+func (f LogFormat) String() string {
+	switch f {
+	case LogStderr:
+		return "stderr"
+	case LogCSV:
+		return "csvlog"
+	case LogJSON:
+		return "jsonlog"
+	}
+	return "LogFormat(" + strconv.Itoa(int(f)) + ")"
+}
+
+// LogEntry is a message read from a server log.
+type LogEntry struct {
+	Time     time.Time
+	User     string
+	Database string
+	Severity string
+	Code     pq.ErrorCode
+	Message  string
+}
+
+// LogReader reads messages from a PostgreSQL server log.
+type LogReader struct {
+	next func() (LogEntry, error)
+}
+
+// NewLogReader returns a reader of a server log in a given format. The stderr
+// format requires the log_line_prefix the log was written with, which must
+// contain the %e escape, i.e. the SQLSTATE.
+func NewLogReader(r io.Reader, format LogFormat, prefix string) (*LogReader, error) {
+	switch format {
+	case LogStderr:
+		re, err := compileLogLinePrefix(prefix)
+		if err != nil {
+			return nil, err
+		}
+		return &LogReader{next: stderrLogReader(r, re)}, nil
+	case LogCSV:
+		return &LogReader{next: csvLogReader(r)}, nil
+	case LogJSON:
+		return &LogReader{next: jsonLogReader(r)}, nil
+	}
+	return nil, fmt.Errorf("pqerror: unknown log format %v", format)
+}
+
+// Next returns the next message. It returns io.EOF at the end of the log.
+// Lines carrying details of a message (DETAIL, HINT, CONTEXT, STATEMENT, ...)
+// are not returned as messages of their own.
+func (r *LogReader) Next() (LogEntry, error) {
+	return r.next()
+}
+
+// csvlog columns, see https://www.postgresql.org/docs/current/runtime-config-logging.html#RUNTIME-CONFIG-LOGGING-CSVLOG.
+const (
+	csvLogTime     = 0
+	csvLogUser     = 1
+	csvLogDatabase = 2
+	csvLogSeverity = 11
+	csvLogCode     = 12
+	csvLogMessage  = 13
+)
+
+func csvLogReader(r io.Reader) func() (LogEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return func() (LogEntry, error) {
+		rec, err := cr.Read()
+		if err != nil {
+			if err != io.EOF {
+				err = fmt.Errorf("pqerror: csvlog: %w", err)
+			}
+			return LogEntry{}, err
+		}
+		if len(rec) <= csvLogMessage {
+			return LogEntry{}, fmt.Errorf("pqerror: csvlog: expected at least %d fields, got %d", csvLogMessage+1, len(rec))
+		}
+		return LogEntry{
+			Time:     parseLogTime(rec[csvLogTime]),
+			User:     rec[csvLogUser],
+			Database: rec[csvLogDatabase],
+			Severity: rec[csvLogSeverity],
+			Code:     pq.ErrorCode(rec[csvLogCode]),
+			Message:  rec[csvLogMessage],
+		}, nil
+	}
+}
+
+func jsonLogReader(r io.Reader) func() (LogEntry, error) {
+	dec := json.NewDecoder(r)
+	return func() (LogEntry, error) {
+		var rec struct {
+			Timestamp string `json:"timestamp"`
+			User      string `json:"user"`
+			Database  string `json:"dbname"`
+			Severity  string `json:"error_severity"`
+			Code      string `json:"state_code"`
+			Message   string `json:"message"`
+		}
+		if err := dec.Decode(&rec); err != nil {
+			if err != io.EOF {
+				err = fmt.Errorf("pqerror: jsonlog: %w", err)
+			}
+			return LogEntry{}, err
+		}
+		return LogEntry{
+			Time:     parseLogTime(rec.Timestamp),
+			User:     rec.User,
+			Database: rec.Database,
+			Severity: rec.Severity,
+			Code:     pq.ErrorCode(rec.Code),
+			Message:  rec.Message,
+		}, nil
+	}
+}
+
+func stderrLogReader(r io.Reader, re *regexp.Regexp) func() (LogEntry, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	groups := make(map[string]int)
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			groups[name] = i
+		}
+	}
+	group := func(m []string, name string) string {
+		if i, ok := groups[name]; ok {
+			return m[i]
+		}
+		return ""
+	}
+	return func() (LogEntry, error) {
+		for s.Scan() {
+			// Skip continuation lines of multi-line messages and lines
+			// carrying message details.
+			m := re.FindStringSubmatch(s.Text())
+			if m == nil {
+				continue
+			}
+			if _, err := ParseSeverity(group(m, "severity")); err != nil {
+				continue
+			}
+			return LogEntry{
+				Time:     parseLogTime(group(m, "time")),
+				User:     group(m, "user"),
+				Database: group(m, "database"),
+				Severity: group(m, "severity"),
+				Code:     pq.ErrorCode(group(m, "code")),
+				Message:  group(m, "message"),
+			}, nil
+		}
+		if err := s.Err(); err != nil {
+			return LogEntry{}, fmt.Errorf("pqerror: stderr log: %w", err)
+		}
+		return LogEntry{}, io.EOF
+	}
+}
+
+// compileLogLinePrefix turns a log_line_prefix into a regular expression
+// matching whole log lines.
+func compileLogLinePrefix(prefix string) (*regexp.Regexp, error) {
+	var (
+		b        strings.Builder
+		optional bool
+		named    = make(map[string]bool)
+	)
+	b.WriteString("^")
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		if c != '%' {
+			b.WriteString(regexp.QuoteMeta(string(c)))
+			continue
+		}
+		// Skip the optional padding, e.g. %-10u.
+		j := i + 1
+		for j < len(prefix) && (prefix[j] == '-' || prefix[j] >= '0' && prefix[j] <= '9') {
+			j++
+		}
+		if j == len(prefix) {
+			return nil, fmt.Errorf("pqerror: invalid log_line_prefix %q", prefix)
+		}
+		padded := j > i+1
+		i = j
+		var expr string
+		switch prefix[i] {
+		case '%':
+			expr = "%"
+		case 'q':
+			// The rest of the prefix is only written by session processes.
+			b.WriteString("(?:")
+			optional = true
+			continue
+		case 't', 's':
+			expr = `\d{4}-\d\d-\d\d \d\d:\d\d:\d\d \S+`
+		case 'm':
+			expr = `\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d+ \S+`
+		case 'n':
+			expr = `\d+\.\d+`
+		case 'p', 'P', 'l', 'x', 'Q':
+			expr = `-?\d*`
+		case 'c':
+			expr = `[0-9a-f]+\.[0-9a-f]+`
+		case 'v':
+			expr = `[0-9/]*`
+		case 'e':
+			expr = `[0-9A-Z]{5}`
+		case 'a', 'u', 'd', 'h', 'r', 'b', 'i':
+			expr = `.*?`
+		default:
+			// Unknown escapes are ignored by the server.
+		}
+		if name := logPrefixGroups[prefix[i]]; name != "" && !named[name] {
+			named[name] = true
+			expr = "(?P<" + name + ">" + expr + ")"
+		}
+		if padded {
+			expr = ` *` + expr + ` *`
+		}
+		b.WriteString(expr)
+	}
+	if optional {
+		b.WriteString(")?")
+	}
+	if !named["code"] {
+		return nil, errors.New("pqerror: log_line_prefix does not contain %e")
+	}
+	b.WriteString(`(?P<severity>[^\s:]+):  ?(?P<message>.*)$`)
+	return regexp.Compile(b.String())
+}
+
+var logPrefixGroups = map[byte]string{
+	't': "time",
+	'm': "time",
+	'n': "time",
+	'u': "user",
+	'd': "database",
+	'e': "code",
+}
+
+// parseLogTime parses a log timestamp as written by %t, %m and %n, or as
+// written to csvlog and jsonlog. It returns the zero time if it fails.
+func parseLogTime(s string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -07", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		sec := int64(f)
+		return time.Unix(sec, int64((f-float64(sec))*1e9)).UTC()
+	}
+	return time.Time{}
+}
+
+// LogStats holds statistics of the errors and warnings found in a server
+// log, i.e. of the messages of severity WARNING, ERROR, FATAL and PANIC.
+type LogStats struct {
+	Total      int
+	ByCode     map[pq.ErrorCode]int
+	ByClass    map[pq.ErrorClass]int
+	ByDatabase map[string]int
+	ByUser     map[string]int
+	// ByTime counts the messages by the start of the time bucket they fall
+	// into. It is only populated if Bucket is not zero.
+	ByTime map[time.Time]int
+	Bucket time.Duration
+}
+
+// NewLogStats returns empty statistics counting the messages in time buckets
+// of a given duration.
+func NewLogStats(bucket time.Duration) *LogStats {
+	return &LogStats{
+		ByCode:     make(map[pq.ErrorCode]int),
+		ByClass:    make(map[pq.ErrorClass]int),
+		ByDatabase: make(map[string]int),
+		ByUser:     make(map[string]int),
+		ByTime:     make(map[time.Time]int),
+		Bucket:     bucket,
+	}
+}
+
+// Add counts a message if it is an error or a warning.
+func (s *LogStats) Add(e LogEntry) {
+	if sev, _ := ParseSeverity(e.Severity); !sev.AtLeast(SeverityWarning) {
+		return
+	}
+	s.Total++
+	s.ByCode[e.Code]++
+	if len(e.Code) == 5 {
+		s.ByClass[e.Code.Class()]++
+	}
+	s.ByDatabase[e.Database]++
+	s.ByUser[e.User]++
+	if s.Bucket > 0 && !e.Time.IsZero() {
+		s.ByTime[e.Time.Truncate(s.Bucket)]++
+	}
+}
+
+// AddAll counts all errors and warnings read from a server log.
+func (s *LogStats) AddAll(r *LogReader) error {
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.Add(e)
+	}
+}
@@ -0,0 +1,223 @@
+package pqerror
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func readLog(t *testing.T, text string, format LogFormat, prefix string) []LogEntry {
+	t.Helper()
+	r, err := NewLogReader(strings.NewReader(text), format, prefix)
+	if err != nil {
+		t.Fatalf("NewLogReader() error = %v", err)
+	}
+	var entries []LogEntry
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+func TestCompileLogLinePrefix(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 0, 0, 123e6, time.UTC)
+	tests := []struct {
+		name   string
+		prefix string
+		line   string
+		want   LogEntry
+	}{
+		{
+			name:   "default with code",
+			prefix: "%m [%p] %e ",
+			line:   "2024-03-01 10:00:00.123 UTC [4242] 22012 ERROR:  division by zero",
+			want:   LogEntry{Time: at, Severity: "ERROR", Code: "22012", Message: "division by zero"},
+		},
+		{
+			name:   "pgbadger",
+			prefix: "%t [%p]: [%l-1] user=%u,db=%d,app=%a,client=%h %e ",
+			line:   "2024-03-01 10:00:00 UTC [4242]: [3-1] user=app,db=shop,app=psql,client=[local] 23505 ERROR:  duplicate key value violates unique constraint \"users_pkey\"",
+			want: LogEntry{
+				Time:     at.Truncate(time.Second),
+				User:     "app",
+				Database: "shop",
+				Severity: "ERROR",
+				Code:     "23505",
+				Message:  `duplicate key value violates unique constraint "users_pkey"`,
+			},
+		},
+		{
+			name:   "session only escapes",
+			prefix: "%m [%p] %q%u@%d %e ",
+			line:   "2024-03-01 10:00:00.123 UTC [4242] app@shop 40P01 ERROR:  deadlock detected",
+			want:   LogEntry{Time: at, User: "app", Database: "shop", Severity: "ERROR", Code: "40P01", Message: "deadlock detected"},
+		},
+		{
+			name:   "session only escapes of background process",
+			prefix: "%m [%p] %q%u@%d %e ",
+			line:   "2024-03-01 10:00:00.123 UTC [17] LOG:  checkpoint starting: time",
+			want:   LogEntry{Time: at, Severity: "LOG", Message: "checkpoint starting: time"},
+		},
+		{
+			name:   "padding and epoch",
+			prefix: "%n %-10u %e ",
+			line:   "1709287200.123 app        57014 ERROR:  canceling statement due to statement timeout",
+			want:   LogEntry{Time: at, User: "app", Severity: "ERROR", Code: "57014", Message: "canceling statement due to statement timeout"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readLog(t, tt.line+"\n", LogStderr, tt.prefix)
+			if len(got) != 1 {
+				t.Fatalf("got %d entries, want 1", len(got))
+			}
+			got[0].Time = got[0].Time.Round(time.Millisecond)
+			if !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("got %+v, want %+v", got[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileLogLinePrefixErrors(t *testing.T) {
+	for _, prefix := range []string{"%m [%p] ", "%m %"} {
+		if _, err := compileLogLinePrefix(prefix); err == nil {
+			t.Errorf("compileLogLinePrefix(%q) succeeded, want error", prefix)
+		}
+	}
+}
+
+func TestLogReaderStderrSkipsDetails(t *testing.T) {
+	text := "2024-03-01 10:00:00.123 UTC [4242] 22012 ERROR:  division by zero\n" +
+		"2024-03-01 10:00:00.123 UTC [4242] 22012 STATEMENT:  SELECT 1/0\n" +
+		"\tFROM t\n" +
+		"2024-03-01 10:00:01.456 UTC [4243] 40P01 ERROR:  deadlock detected\n"
+	var got []pq.ErrorCode
+	for _, e := range readLog(t, text, LogStderr, "%m [%p] %e ") {
+		got = append(got, e.Code)
+	}
+	if want := []pq.ErrorCode{"22012", "40P01"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("codes = %v, want %v", got, want)
+	}
+}
+
+func TestLogReaderCSV(t *testing.T) {
+	text := `2024-03-01 10:00:00.123 UTC,"app","shop",4242,"[local]",65e1a2b0.1092,1,"SELECT",2024-03-01 09:59:00 UTC,3/7,0,ERROR,22012,"division by zero",,,,,,"SELECT 1/0",,,"psql","client backend",,0
+2024-03-01 10:00:01.000 UTC,"app","shop",4242,"[local]",65e1a2b0.1092,2,"INSERT",2024-03-01 09:59:00 UTC,3/8,0,ERROR,23505,"duplicate key value violates unique constraint ""users_pkey""","Key (id)=(1) already exists.",,,,,"INSERT INTO users VALUES (1)",,"_bt_check_unique, nbtinsert.c:666","psql","client backend",,0
+`
+	want := []LogEntry{
+		{
+			Time:     time.Date(2024, 3, 1, 10, 0, 0, 123e6, time.UTC),
+			User:     "app",
+			Database: "shop",
+			Severity: "ERROR",
+			Code:     "22012",
+			Message:  "division by zero",
+		},
+		{
+			Time:     time.Date(2024, 3, 1, 10, 0, 1, 0, time.UTC),
+			User:     "app",
+			Database: "shop",
+			Severity: "ERROR",
+			Code:     "23505",
+			Message:  `duplicate key value violates unique constraint "users_pkey"`,
+		},
+	}
+	got := readLog(t, text, LogCSV, "")
+	for i := range got {
+		got[i].Time = got[i].Time.UTC()
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	r, _ := NewLogReader(strings.NewReader("2024-03-01 10:00:00 UTC,app,shop\n"), LogCSV, "")
+	if _, err := r.Next(); err == nil || err == io.EOF {
+		t.Errorf("Next() of short record error = %v, want error", err)
+	}
+}
+
+func TestLogReaderJSON(t *testing.T) {
+	text := `{"timestamp":"2024-03-01 10:00:00.123 UTC","user":"app","dbname":"shop","pid":4242,"error_severity":"ERROR","state_code":"22012","message":"division by zero","statement":"SELECT 1/0"}
+{"timestamp":"2024-03-01 10:00:05.000 UTC","pid":17,"backend_type":"checkpointer","error_severity":"LOG","message":"checkpoint starting: time"}
+`
+	want := []LogEntry{
+		{
+			Time:     time.Date(2024, 3, 1, 10, 0, 0, 123e6, time.UTC),
+			User:     "app",
+			Database: "shop",
+			Severity: "ERROR",
+			Code:     "22012",
+			Message:  "division by zero",
+		},
+		{
+			Time:     time.Date(2024, 3, 1, 10, 0, 5, 0, time.UTC),
+			Severity: "LOG",
+			Message:  "checkpoint starting: time",
+		},
+	}
+	got := readLog(t, text, LogJSON, "")
+	for i := range got {
+		got[i].Time = got[i].Time.UTC()
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLogStats(t *testing.T) {
+	at := func(min, sec int) time.Time {
+		return time.Date(2024, 3, 1, 10, min, sec, 0, time.UTC)
+	}
+	entries := []LogEntry{
+		{Time: at(0, 5), User: "app", Database: "shop", Severity: "ERROR", Code: "23505"},
+		{Time: at(0, 59), User: "app", Database: "shop", Severity: "ERROR", Code: "23503"},
+		{Time: at(1, 0), User: "batch", Database: "shop", Severity: "FATAL", Code: "57P01"},
+		{Time: at(1, 30), User: "app", Database: "crm", Severity: "WARNING", Code: "01000"},
+		{Time: at(1, 30), User: "app", Database: "crm", Severity: "LOG", Code: "00000"},
+		{Time: at(1, 30), User: "app", Database: "crm", Severity: "NOTICE", Code: "00000"},
+		{User: "app", Database: "shop", Severity: "ERROR", Code: "23505"},
+	}
+
+	s := NewLogStats(time.Minute)
+	for _, e := range entries {
+		s.Add(e)
+	}
+	if s.Total != 5 {
+		t.Errorf("Total = %d, want 5", s.Total)
+	}
+	if want := map[pq.ErrorCode]int{"23505": 2, "23503": 1, "57P01": 1, "01000": 1}; !reflect.DeepEqual(s.ByCode, want) {
+		t.Errorf("ByCode = %v, want %v", s.ByCode, want)
+	}
+	if want := map[pq.ErrorClass]int{"23": 3, "57": 1, "01": 1}; !reflect.DeepEqual(s.ByClass, want) {
+		t.Errorf("ByClass = %v, want %v", s.ByClass, want)
+	}
+	if want := map[string]int{"shop": 4, "crm": 1}; !reflect.DeepEqual(s.ByDatabase, want) {
+		t.Errorf("ByDatabase = %v, want %v", s.ByDatabase, want)
+	}
+	if want := map[string]int{"app": 4, "batch": 1}; !reflect.DeepEqual(s.ByUser, want) {
+		t.Errorf("ByUser = %v, want %v", s.ByUser, want)
+	}
+	// Messages without a time are not bucketed.
+	if want := map[time.Time]int{at(0, 0): 2, at(1, 0): 2}; !reflect.DeepEqual(s.ByTime, want) {
+		t.Errorf("ByTime = %v, want %v", s.ByTime, want)
+	}
+
+	s = NewLogStats(0)
+	for _, e := range entries {
+		s.Add(e)
+	}
+	if len(s.ByTime) != 0 {
+		t.Errorf("ByTime without bucket = %v, want empty", s.ByTime)
+	}
+}
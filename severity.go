@@ -0,0 +1,147 @@
+package pqerror
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Severity is the severity of a message sent by the server. Severities are
+// ordered the way the server orders them, from SeverityDebug to
+// SeverityPanic.
+type Severity int
+
+const (
+	SeverityUnknown Severity = iota
+	SeverityDebug
+	SeverityLog
+	SeverityInfo
+	SeverityNotice
+	SeverityWarning
+	SeverityError
+	SeverityFatal
+	SeverityPanic
+)
+
+var severityNames = [...]string{
+	SeverityUnknown: "UNKNOWN",
+	SeverityDebug:   pq.Edebug,
+	SeverityLog:     pq.Elog,
+	SeverityInfo:    pq.Einfo,
+	SeverityNotice:  pq.Enotice,
+	SeverityWarning: pq.Ewarning,
+	SeverityError:   "ERROR",
+	SeverityFatal:   pq.Efatal,
+	SeverityPanic:   pq.Epanic,
+}
+
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return "Severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// AtLeast reports whether a severity is the same as or higher than another.
+func (s Severity) AtLeast(other Severity) bool {
+	return s >= other
+}
+
+// IsError reports whether a severity is that of an error, i.e. ERROR, FATAL
+// or PANIC, as opposed to a notice.
+func (s Severity) IsError() bool {
+	return s >= SeverityError
+}
+
+// ParseSeverity parses a severity as sent by the server in either the
+// non-localized (V) or the localized (S) field of a message, e.g. "ERROR" or
+// "FEHLER". Only the most common translations are recognized.
+func ParseSeverity(severity string) (Severity, error) {
+	s := strings.ToUpper(strings.TrimSpace(severity))
+	if len(s) == len("DEBUG1") && strings.HasPrefix(s, "DEBUG") && s[5] >= '1' && s[5] <= '5' {
+		return SeverityDebug, nil
+	}
+	for sev, name := range severityNames {
+		if sev != int(SeverityUnknown) && s == name {
+			return Severity(sev), nil
+		}
+	}
+	if sev, ok := localizedSeverities[s]; ok {
+		return sev, nil
+	}
+	return SeverityUnknown, fmt.Errorf("pqerror: unknown severity %q", severity)
+}
+
+// SeverityOf returns the severity of an error. If the severity of
+// a *pq.Error cannot be parsed, e.g. because it is localized in an
+// unrecognized language, it is derived from the code: warnings of class 01
+// are WARNING, other successful completion and no data classes are NOTICE
+// and everything else is ERROR. SeverityOf returns SeverityUnknown if there
+// is no *pq.Error in the chain of the error.
+//
+// Note that pq keeps the localized severity only.
+func SeverityOf(err error) Severity {
+	pqerr, ok := asPqError(err)
+	if !ok {
+		return SeverityUnknown
+	}
+	if sev, err := ParseSeverity(pqerr.Severity); err == nil {
+		return sev
+	}
+	if len(pqerr.Code) == 5 {
+		switch pqerr.Code.Class() {
+		case ClassWarning:
+			return SeverityWarning
+		case ClassSuccessfulCompletion, ClassNoData:
+			return SeverityNotice
+		}
+	}
+	return SeverityError
+}
+
+// IsFatal reports whether an error is of severity FATAL or PANIC, that is
+// whether the server terminated the session.
+func IsFatal(err error) bool {
+	return SeverityOf(err).AtLeast(SeverityFatal)
+}
+
+// localizedSeverities maps the translations of severities of the most common
+// server message languages.
+var localizedSeverities = map[string]Severity{
+	// de
+	"HINWEIS": SeverityNotice,
+	"WARNUNG": SeverityWarning,
+	"FEHLER":  SeverityError,
+	"PANIK":   SeverityPanic,
+	// fr
+	"ATTENTION": SeverityWarning,
+	"ERREUR":    SeverityError,
+	// ja
+	"デバッグ":   SeverityDebug,
+	"ログ":     SeverityLog,
+	"情報":     SeverityInfo,
+	"注意":     SeverityNotice,
+	"警告":     SeverityWarning,
+	"エラー":    SeverityError,
+	"致命的エラー": SeverityFatal,
+	// pt_BR
+	"ERRO":   SeverityError,
+	"PÂNICO": SeverityPanic,
+	// ru
+	"ОТЛАДКА":        SeverityDebug,
+	"СООБЩЕНИЕ":      SeverityLog,
+	"ИНФОРМАЦИЯ":     SeverityInfo,
+	"ЗАМЕЧАНИЕ":      SeverityNotice,
+	"ПРЕДУПРЕЖДЕНИЕ": SeverityWarning,
+	"ОШИБКА":         SeverityError,
+	"ВАЖНО":          SeverityFatal,
+	"ПАНИКА":         SeverityPanic,
+	// zh_CN, shares 注意 and 警告 with ja
+	"调试":   SeverityDebug,
+	"日志":   SeverityLog,
+	"信息":   SeverityInfo,
+	"错误":   SeverityError,
+	"致命错误": SeverityFatal,
+}
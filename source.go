@@ -0,0 +1,182 @@
+package pqerror
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SourceURL returns the URL of the line of the PostgreSQL source code an
+// error was raised at, e.g.
+//
+//	https://github.com/postgres/postgres/blob/REL_12_4/src/backend/access/nbtree/nbtinsert.c#L570
+//
+// The server version is the server_version setting, e.g. "12.4" or
+// "12.4 (Debian 12.4-1.pgdg100+1)", or the server_version_num setting, e.g.
+// "120004".
+//
+// The server reports the base name of the source file only, so the directory
+// is looked up in a table of the source files errors are most often raised
+// in, which knows the layouts of PostgreSQL 12 to 17. SourceURL returns an
+// error if there is no *pq.Error in the chain of the error, if the error has
+// no File and Line, if the version cannot be parsed, if its layout is not
+// known, e.g. of PostgreSQL 11 or of a development version, or if the file is
+// unknown or does not exist in the version, e.g. tuptoaster.c since
+// PostgreSQL 13.
+func SourceURL(err error, serverVersion string) (string, error) {
+	pqerr, ok := asPqError(err)
+	if !ok {
+		return "", fmt.Errorf("pqerror: not a PostgreSQL error")
+	}
+	if pqerr.File == "" || pqerr.Line == "" {
+		return "", fmt.Errorf("pqerror: no source location")
+	}
+	tag, major, err := sourceTag(serverVersion)
+	if err != nil {
+		return "", err
+	}
+	if tag == "master" || major < minSourceLayout || major > maxSourceLayout {
+		return "", fmt.Errorf("pqerror: unknown source layout of server version %q", serverVersion)
+	}
+	dir, ok := sourceDir(pqerr.File, major)
+	if !ok {
+		return "", fmt.Errorf("pqerror: unknown source file %s in PostgreSQL %d", pqerr.File, major)
+	}
+	return sourceRepository + "/blob/" + tag + "/" + dir + "/" + pqerr.File + "#L" + pqerr.Line, nil
+}
+
+const sourceRepository = "https://github.com/postgres/postgres"
+
+var (
+	serverVersionRe    = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?(devel|(?:beta|rc)\d+)?`)
+	serverVersionNumRe = regexp.MustCompile(`^\d{5,6}$`)
+)
+
+// sourceTag returns the git tag and the major version of a server version.
+func sourceTag(version string) (string, int, error) {
+	version = strings.TrimSpace(version)
+	if serverVersionNumRe.MatchString(version) {
+		n, _ := strconv.Atoi(version)
+		if n >= 100000 {
+			return fmt.Sprintf("REL_%d_%d", n/10000, n%10000), n / 10000, nil
+		}
+		return fmt.Sprintf("REL%d_%d_%d", n/10000, n/100%100, n%100), n / 10000, nil
+	}
+	m := serverVersionRe.FindStringSubmatch(version)
+	if m == nil {
+		return "", 0, fmt.Errorf("pqerror: invalid server version %q", version)
+	}
+	major, _ := strconv.Atoi(m[1])
+	switch {
+	case m[4] == "devel":
+		return "master", major, nil
+	case m[4] != "" && major >= 10:
+		return fmt.Sprintf("REL_%d_%s", major, strings.ToUpper(m[4])), major, nil
+	case m[4] != "":
+		return fmt.Sprintf("REL%d_%s_%s", major, m[2], strings.ToUpper(m[4])), major, nil
+	case major >= 10 && m[2] != "":
+		return fmt.Sprintf("REL_%d_%s", major, m[2]), major, nil
+	case major < 10 && m[3] != "":
+		return fmt.Sprintf("REL%d_%s_%s", major, m[2], m[3]), major, nil
+	}
+	return "", 0, fmt.Errorf("pqerror: invalid server version %q", version)
+}
+
+// sourceDirs maps the base names of source files to their directories. Base
+// names shared by several files, e.g. analyze.c, are left out.
+var sourceDirs = make(map[string]string)
+
+func init() {
+	for dir, files := range sourceFiles {
+		for _, file := range strings.Fields(files) {
+			sourceDirs[file] = dir
+		}
+	}
+}
+
+// The major versions whose source layout is known: sourceFiles and
+// sourceMoves are checked against them.
+const (
+	minSourceLayout = 12
+	maxSourceLayout = 17
+)
+
+// sourceDir returns the directory of a source file in a given major version.
+func sourceDir(file string, major int) (string, bool) {
+	dir, ok := sourceDirs[file]
+	for _, m := range sourceMoves {
+		if m.file == file && major >= m.major {
+			dir, ok = m.dir, m.dir != ""
+		}
+	}
+	return dir, ok
+}
+
+// sourceMove records that a source file moved to another directory, was
+// added, or was removed if dir is empty, in a given major version.
+type sourceMove struct {
+	file  string
+	major int
+	dir   string
+}
+
+// sourceMoves lists the changes to sourceFiles since PostgreSQL 12, in
+// version order.
+var sourceMoves = []sourceMove{
+	// The TOAST code was split up in PostgreSQL 13.
+	{"tuptoaster.c", 13, ""},
+	{"heaptoast.c", 13, "src/backend/access/heap"},
+	{"detoast.c", 13, "src/backend/access/common"},
+	{"toast_internals.c", 13, "src/backend/access/common"},
+	{"wchar.c", 13, "src/common"},
+	{"pgstat.c", 15, "src/backend/utils/activity"},
+}
+
+// See https://github.com/postgres/postgres/tree/REL_12_STABLE/src.
+var sourceFiles = map[string]string{
+	"src/backend/access/brin":          "brin.c brin_revmap.c",
+	"src/backend/access/common":        "heaptuple.c indextuple.c reloptions.c tupdesc.c",
+	"src/backend/access/gin":           "ginbtree.c gindatapage.c ginentrypage.c ginget.c gininsert.c",
+	"src/backend/access/gist":          "gist.c gistbuild.c gistutil.c gistvacuum.c",
+	"src/backend/access/hash":          "hash.c hashinsert.c hashovfl.c hashpage.c hashutil.c",
+	"src/backend/access/heap":          "heapam.c heapam_handler.c hio.c pruneheap.c rewriteheap.c tuptoaster.c vacuumlazy.c visibilitymap.c",
+	"src/backend/access/index":         "genam.c indexam.c",
+	"src/backend/access/nbtree":        "nbtinsert.c nbtpage.c nbtree.c nbtsearch.c nbtsort.c nbtutils.c",
+	"src/backend/access/spgist":        "spgdoinsert.c spgutils.c",
+	"src/backend/access/table":         "table.c tableam.c",
+	"src/backend/access/transam":       "clog.c commit_ts.c multixact.c slru.c subtrans.c transam.c twophase.c varsup.c xact.c xlog.c xloginsert.c xlogreader.c xlogutils.c",
+	"src/backend/catalog":              "aclchk.c catalog.c dependency.c heap.c index.c indexing.c namespace.c objectaddress.c pg_constraint.c pg_depend.c pg_inherits.c pg_proc.c pg_shdepend.c pg_type.c toasting.c",
+	"src/backend/commands":             "alter.c async.c cluster.c copy.c createas.c dbcommands.c explain.c extension.c functioncmds.c indexcmds.c lockcmds.c matview.c portalcmds.c prepare.c schemacmds.c sequence.c tablecmds.c tablespace.c trigger.c typecmds.c user.c vacuum.c variable.c view.c",
+	"src/backend/executor":             "execCurrent.c execExpr.c execExprInterp.c execIndexing.c execMain.c execPartition.c execReplication.c execTuples.c execUtils.c functions.c nodeAgg.c nodeHash.c nodeHashjoin.c nodeLockRows.c nodeModifyTable.c nodeSubplan.c spi.c",
+	"src/backend/libpq":                "auth.c be-fsstubs.c be-secure.c be-secure-openssl.c hba.c pqcomm.c pqformat.c",
+	"src/backend/optimizer/plan":       "createplan.c planner.c setrefs.c subselect.c",
+	"src/backend/optimizer/util":       "clauses.c plancat.c",
+	"src/backend/parser":               "gram.y parse_agg.c parse_clause.c parse_coerce.c parse_collate.c parse_cte.c parse_expr.c parse_func.c parse_node.c parse_oper.c parse_relation.c parse_target.c parse_type.c parse_utilcmd.c scan.l",
+	"src/backend/partitioning":         "partbounds.c partprune.c",
+	"src/backend/postmaster":           "autovacuum.c bgworker.c checkpointer.c pgarch.c pgstat.c postmaster.c",
+	"src/backend/replication":          "slot.c slotfuncs.c syncrep.c walreceiver.c walsender.c",
+	"src/backend/replication/logical":  "decode.c logical.c logicalfuncs.c origin.c reorderbuffer.c snapbuild.c tablesync.c worker.c",
+	"src/backend/rewrite":              "rewriteDefine.c rewriteHandler.c",
+	"src/backend/storage/buffer":       "bufmgr.c freelist.c localbuf.c",
+	"src/backend/storage/file":         "buffile.c fd.c",
+	"src/backend/storage/freespace":    "freespace.c",
+	"src/backend/storage/ipc":          "dsm.c ipc.c latch.c procarray.c shm_mq.c shmem.c sinvaladt.c standby.c",
+	"src/backend/storage/large_object": "inv_api.c",
+	"src/backend/storage/lmgr":         "deadlock.c lmgr.c lock.c lwlock.c predicate.c proc.c s_lock.c",
+	"src/backend/storage/page":         "bufpage.c checksum.c",
+	"src/backend/storage/smgr":         "md.c smgr.c",
+	"src/backend/tcop":                 "fastpath.c postgres.c pquery.c utility.c",
+	"src/backend/utils/adt":            "acl.c arrayfuncs.c bool.c cash.c date.c datetime.c dbsize.c encode.c enum.c float.c formatting.c genfile.c int.c int8.c json.c jsonb.c jsonb_util.c jsonfuncs.c jsonpath_exec.c misc.c network.c numeric.c oid.c rangetypes.c regexp.c regproc.c ri_triggers.c ruleutils.c selfuncs.c tid.c timestamp.c uuid.c varchar.c varlena.c xml.c",
+	"src/backend/utils/cache":          "catcache.c inval.c lsyscache.c plancache.c relcache.c relmapper.c syscache.c typcache.c",
+	"src/backend/utils/error":          "assert.c elog.c",
+	"src/backend/utils/fmgr":           "dfmgr.c fmgr.c funcapi.c",
+	"src/backend/utils/hash":           "dynahash.c",
+	"src/backend/utils/init":           "miscinit.c postinit.c",
+	"src/backend/utils/mb":             "mbutils.c wchar.c",
+	"src/backend/utils/misc":           "guc.c superuser.c",
+	"src/backend/utils/mmgr":           "aset.c dsa.c mcxt.c portalmem.c",
+	"src/backend/utils/sort":           "logtape.c sharedtuplestore.c tuplesort.c tuplestore.c",
+	"src/backend/utils/time":           "combocid.c snapmgr.c",
+	"src/pl/plpgsql/src":               "pl_comp.c pl_exec.c pl_funcs.c pl_gram.y pl_handler.c",
+}
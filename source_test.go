@@ -0,0 +1,80 @@
+package pqerror
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestSourceURL(t *testing.T) {
+	tests := []struct {
+		file    string
+		version string
+		want    string
+	}{
+		{"nbtinsert.c", "12.4", "https://github.com/postgres/postgres/blob/REL_12_4/src/backend/access/nbtree/nbtinsert.c#L42"},
+		{"nbtinsert.c", "16.2 (Debian 16.2-1.pgdg120+2)", "https://github.com/postgres/postgres/blob/REL_16_2/src/backend/access/nbtree/nbtinsert.c#L42"},
+		{"nbtinsert.c", "160002", "https://github.com/postgres/postgres/blob/REL_16_2/src/backend/access/nbtree/nbtinsert.c#L42"},
+		{"nbtinsert.c", "17beta1", "https://github.com/postgres/postgres/blob/REL_17_BETA1/src/backend/access/nbtree/nbtinsert.c#L42"},
+		{"wchar.c", "12.4", "https://github.com/postgres/postgres/blob/REL_12_4/src/backend/utils/mb/wchar.c#L42"},
+		{"wchar.c", "16.2", "https://github.com/postgres/postgres/blob/REL_16_2/src/common/wchar.c#L42"},
+		{"tuptoaster.c", "12.4", "https://github.com/postgres/postgres/blob/REL_12_4/src/backend/access/heap/tuptoaster.c#L42"},
+		{"detoast.c", "13.1", "https://github.com/postgres/postgres/blob/REL_13_1/src/backend/access/common/detoast.c#L42"},
+		{"pgstat.c", "14.5", "https://github.com/postgres/postgres/blob/REL_14_5/src/backend/postmaster/pgstat.c#L42"},
+		{"pgstat.c", "15.0", "https://github.com/postgres/postgres/blob/REL_15_0/src/backend/utils/activity/pgstat.c#L42"},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("query: %w", &pq.Error{File: tt.file, Line: "42"})
+		got, uerr := SourceURL(err, tt.version)
+		if uerr != nil || got != tt.want {
+			t.Errorf("SourceURL(%s, %q) = %q, %v, want %q", tt.file, tt.version, got, uerr, tt.want)
+		}
+	}
+}
+
+func TestSourceURLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		version string
+	}{
+		{"non-pq error", fmt.Errorf("boom"), "16.2"},
+		{"no location", &pq.Error{Code: InternalError}, "16.2"},
+		{"unknown file", &pq.Error{File: "nosuchfile.c", Line: "1"}, "16.2"},
+		{"removed file", &pq.Error{File: "tuptoaster.c", Line: "1"}, "16.2"},
+		{"file added later", &pq.Error{File: "heaptoast.c", Line: "1"}, "12.4"},
+		{"invalid version", &pq.Error{File: "nbtinsert.c", Line: "1"}, "sixteen"},
+		{"old layout", &pq.Error{File: "nbtinsert.c", Line: "1"}, "11.9"},
+		{"old version number", &pq.Error{File: "nbtinsert.c", Line: "1"}, "90624"},
+		{"future layout", &pq.Error{File: "nbtinsert.c", Line: "1"}, "18.0"},
+		{"development version", &pq.Error{File: "nbtinsert.c", Line: "1"}, "17devel"},
+	}
+	for _, tt := range tests {
+		if url, err := SourceURL(tt.err, tt.version); err == nil {
+			t.Errorf("%s: SourceURL() = %q, want error", tt.name, url)
+		}
+	}
+}
+
+func TestSourceTag(t *testing.T) {
+	tests := []struct {
+		version string
+		tag     string
+		major   int
+	}{
+		{"12.4", "REL_12_4", 12},
+		{"120004", "REL_12_4", 12},
+		{"9.6.24", "REL9_6_24", 9},
+		{"90624", "REL9_6_24", 9},
+		{"9.6beta2", "REL9_6_BETA2", 9},
+		{"16rc1", "REL_16_RC1", 16},
+		{"17devel", "master", 17},
+	}
+	for _, tt := range tests {
+		tag, major, err := sourceTag(tt.version)
+		if err != nil || tag != tt.tag || major != tt.major {
+			t.Errorf("sourceTag(%q) = %q, %d, %v, want %q, %d", tt.version, tag, major, err, tt.tag, tt.major)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package pqerror
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Frame is a frame of the call stack of an error raised by database code,
+// parsed from a line of the CONTEXT field, e.g.
+//
+//	PL/pgSQL function billing.charge(integer) line 12 at RAISE
+type Frame struct {
+	// Language is the language of the function, "PL/pgSQL" or "SQL", or an
+	// empty string if the frame is not a function call.
+	Language string
+	// Function is the name of the function, possibly schema-qualified, e.g.
+	// "billing.charge", or "inline_code_block" for a DO block.
+	Function string
+	// Args lists the argument types of the function, e.g. ["integer"]. The
+	// argument types of SQL functions are not reported by the server.
+	Args []string
+	// Line is the line number within a PL/pgSQL function or the statement
+	// number within a SQL function, or 0 if unknown.
+	Line int
+	// Statement is the kind of the PL/pgSQL statement being executed, e.g.
+	// "RAISE" or "PERFORM", or "SQL statement" for the other frames
+	// executing SQL.
+	Statement string
+	// SQL is the text of the SQL statement being executed by the frame, if
+	// known.
+	SQL string
+	// Text is the original text of the frame.
+	Text string
+}
+
+// Signature returns the name and the argument types of the function of
+// a frame, e.g. "billing.charge(integer)".
+func (f Frame) Signature() string {
+	if f.Args == nil {
+		return f.Function
+	}
+	return f.Function + "(" + strings.Join(f.Args, ", ") + ")"
+}
+
+// Stack returns the call stack of an error, innermost frame first, parsed
+// from its CONTEXT field, see ParseContext. It returns nil if there is no
+// *pq.Error in the chain of the error.
+func Stack(err error) []Frame {
+	pqerr, ok := asPqError(err)
+	if !ok {
+		return nil
+	}
+	return ParseContext(pqerr.Where)
+}
+
+// ParseContext parses the CONTEXT field of an error into a call stack,
+// innermost frame first. The SQL statements reported by "SQL statement"
+// lines are attached to the frame of the function executing them, e.g.
+//
+//	PL/pgSQL function inner() line 3 at RAISE
+//	SQL statement "SELECT inner()"
+//	PL/pgSQL function outer() line 5 at PERFORM
+//
+// yields two frames, the second one executing "SELECT inner()". Lines that
+// are not recognized are returned as frames with their Text only.
+func ParseContext(where string) []Frame {
+	var (
+		frames  []Frame
+		pending *Frame // a statement frame waiting for its function frame
+	)
+	for _, line := range splitContext(where) {
+		if m := contextStatementRe.FindStringSubmatch(line); m != nil {
+			if pending != nil {
+				frames = append(frames, *pending)
+			}
+			pending = &Frame{
+				Statement: m[1],
+				SQL:       strings.TrimSuffix(line[len(m[0]):], `"`),
+				Text:      line,
+			}
+			continue
+		}
+		f := parseFrame(line)
+		if pending != nil {
+			if f.Function != "" && f.SQL == "" {
+				f.SQL = pending.SQL
+			} else {
+				frames = append(frames, *pending)
+			}
+			pending = nil
+		}
+		frames = append(frames, f)
+	}
+	if pending != nil {
+		frames = append(frames, *pending)
+	}
+	return frames
+}
+
+// splitContext splits the CONTEXT field of an error into its lines, except
+// that a statement spanning several lines, e.g.
+//
+//	SQL statement "UPDATE accounts
+//	    SET balance = balance - 10"
+//	PL/pgSQL function withdraw(integer) line 4 at SQL statement
+//
+// is kept in one piece. The server does not escape the statements it quotes,
+// so a statement ends at the first line ending in a double quote that is
+// followed by another frame or by the end of the field, and failing that at
+// the first line ending in a double quote.
+func splitContext(where string) []string {
+	var entries []string
+	lines := strings.Split(where, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" {
+			continue
+		}
+		m := contextStatementRe.FindStringIndex(line)
+		if m == nil {
+			entries = append(entries, line)
+			continue
+		}
+		end, first := len(lines)-1, -1
+		for j := i; j < len(lines); j++ {
+			if !strings.HasSuffix(lines[j], `"`) || j == i && len(line) == m[1] {
+				continue
+			}
+			if first < 0 {
+				first = j
+			}
+			if j+1 == len(lines) || startsFrame(lines[j+1]) {
+				end, first = j, -1
+				break
+			}
+		}
+		if first >= 0 {
+			end = first
+		}
+		entries = append(entries, strings.Join(lines[i:end+1], "\n"))
+		i = end
+	}
+	return entries
+}
+
+// startsFrame reports whether a line of the CONTEXT field begins a frame
+// ParseContext recognizes.
+func startsFrame(line string) bool {
+	return strings.HasPrefix(line, plpgsqlFramePrefix) ||
+		contextStatementRe.MatchString(line) ||
+		contextSQLFunctionRe.MatchString(line) ||
+		contextCompileRe.MatchString(line)
+}
+
+// contextStatementKinds lists the prefixes the server quotes a statement or
+// an expression being executed with in the CONTEXT field, e.g. "SQL
+// statement" in `SQL statement "SELECT inner()"`.
+var contextStatementKinds = []string{
+	"SQL statement",
+	"SQL expression",
+	"PL/pgSQL expression",
+	"PL/pgSQL assignment",
+}
+
+var (
+	contextStatementRe   = regexp.MustCompile(`^(` + strings.Join(contextStatementKinds, "|") + `) "`)
+	contextSQLFunctionRe = regexp.MustCompile(`^SQL function "(.*)"(?: statement (\d+))?`)
+	contextCompileRe     = regexp.MustCompile(`^compilation of PL/pgSQL function "(.*)" near line (\d+)$`)
+	contextPlpgsqlRestRe = regexp.MustCompile(`^ line (\d+)(?: at (.+))?`)
+)
+
+const plpgsqlFramePrefix = "PL/pgSQL function "
+
+func parseFrame(line string) Frame {
+	f := Frame{Text: line}
+	switch {
+	case strings.HasPrefix(line, plpgsqlFramePrefix):
+		f.Language = "PL/pgSQL"
+		rest := line[len(plpgsqlFramePrefix):]
+		f.Function, f.Args, rest = parseSignature(rest)
+		if m := contextPlpgsqlRestRe.FindStringSubmatch(rest); m != nil {
+			f.Line, _ = strconv.Atoi(m[1])
+			f.Statement = m[2]
+		}
+	case contextSQLFunctionRe.MatchString(line):
+		m := contextSQLFunctionRe.FindStringSubmatch(line)
+		f.Language = "SQL"
+		f.Function = m[1]
+		f.Line, _ = strconv.Atoi(m[2])
+	case contextCompileRe.MatchString(line):
+		m := contextCompileRe.FindStringSubmatch(line)
+		f.Language = "PL/pgSQL"
+		f.Function = m[1]
+		f.Line, _ = strconv.Atoi(m[2])
+	}
+	return f
+}
+
+// parseSignature parses a function signature as printed by the server, e.g.
+// `billing.charge(integer, text)`, at the beginning of a string and returns
+// the rest of the string.
+func parseSignature(s string) (string, []string, string) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ' ':
+			return s[:i], nil, s[i:]
+		case c == '(':
+			end := strings.IndexByte(s[i:], ')')
+			if end < 0 {
+				return s, nil, ""
+			}
+			args := []string{}
+			if list := s[i+1 : i+end]; list != "" {
+				args = strings.Split(list, ",")
+				for j := range args {
+					args[j] = strings.TrimSpace(args[j])
+				}
+			}
+			return s[:i], args, s[i+end+1:]
+		}
+	}
+	return s, nil, ""
+}
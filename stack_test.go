@@ -0,0 +1,174 @@
+package pqerror
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestParseContext(t *testing.T) {
+	tests := []struct {
+		name  string
+		where string
+		want  []Frame
+	}{
+		{
+			name:  "empty",
+			where: "",
+			want:  nil,
+		},
+		{
+			name: "nested frames",
+			where: "PL/pgSQL function billing.charge(integer,text) line 12 at RAISE\n" +
+				"SQL statement \"SELECT billing.charge(42, 'EUR')\"\n" +
+				"PL/pgSQL function checkout(integer) line 5 at PERFORM",
+			want: []Frame{
+				{
+					Language:  "PL/pgSQL",
+					Function:  "billing.charge",
+					Args:      []string{"integer", "text"},
+					Line:      12,
+					Statement: "RAISE",
+					Text:      "PL/pgSQL function billing.charge(integer,text) line 12 at RAISE",
+				},
+				{
+					Language:  "PL/pgSQL",
+					Function:  "checkout",
+					Args:      []string{"integer"},
+					Line:      5,
+					Statement: "PERFORM",
+					SQL:       "SELECT billing.charge(42, 'EUR')",
+					Text:      "PL/pgSQL function checkout(integer) line 5 at PERFORM",
+				},
+			},
+		},
+		{
+			name:  "inline code block",
+			where: "PL/pgSQL function inline_code_block line 3 at RAISE",
+			want: []Frame{{
+				Language:  "PL/pgSQL",
+				Function:  "inline_code_block",
+				Line:      3,
+				Statement: "RAISE",
+				Text:      "PL/pgSQL function inline_code_block line 3 at RAISE",
+			}},
+		},
+		{
+			name: "SQL functions",
+			where: "SQL function \"add_one\" statement 1\n" +
+				"SQL function \"add_two\" during startup",
+			want: []Frame{
+				{Language: "SQL", Function: "add_one", Line: 1, Text: "SQL function \"add_one\" statement 1"},
+				{Language: "SQL", Function: "add_two", Text: "SQL function \"add_two\" during startup"},
+			},
+		},
+		{
+			name:  "compilation",
+			where: "compilation of PL/pgSQL function \"transfer\" near line 7",
+			want: []Frame{{
+				Language: "PL/pgSQL",
+				Function: "transfer",
+				Line:     7,
+				Text:     "compilation of PL/pgSQL function \"transfer\" near line 7",
+			}},
+		},
+		{
+			name: "multi-line statement",
+			where: "SQL statement \"SELECT \"name\"\n" +
+				"    FROM users\n" +
+				"    WHERE id = 1\"\n" +
+				"PL/pgSQL function lookup(integer) line 3 at SQL statement",
+			want: []Frame{{
+				Language:  "PL/pgSQL",
+				Function:  "lookup",
+				Args:      []string{"integer"},
+				Line:      3,
+				Statement: "SQL statement",
+				SQL:       "SELECT \"name\"\n    FROM users\n    WHERE id = 1",
+				Text:      "PL/pgSQL function lookup(integer) line 3 at SQL statement",
+			}},
+		},
+		{
+			name: "multi-line statement ending in a quoted identifier",
+			where: "SQL statement \"UPDATE accounts\n" +
+				"    SET balance = 0 WHERE \"id\"\"\n" +
+				"PL/pgSQL function reset() line 2 at SQL statement",
+			want: []Frame{{
+				Language:  "PL/pgSQL",
+				Function:  "reset",
+				Args:      []string{},
+				Line:      2,
+				Statement: "SQL statement",
+				SQL:       "UPDATE accounts\n    SET balance = 0 WHERE \"id\"",
+				Text:      "PL/pgSQL function reset() line 2 at SQL statement",
+			}},
+		},
+		{
+			name: "PL/pgSQL expression and assignment",
+			where: "PL/pgSQL expression \"x = 'secret'\"\n" +
+				"PL/pgSQL function check_x(text) line 4 at IF\n" +
+				"PL/pgSQL assignment \"total := total + 1\"\n" +
+				"PL/pgSQL function count_rows() line 6 at assignment",
+			want: []Frame{
+				{
+					Language:  "PL/pgSQL",
+					Function:  "check_x",
+					Args:      []string{"text"},
+					Line:      4,
+					Statement: "IF",
+					SQL:       "x = 'secret'",
+					Text:      "PL/pgSQL function check_x(text) line 4 at IF",
+				},
+				{
+					Language:  "PL/pgSQL",
+					Function:  "count_rows",
+					Args:      []string{},
+					Line:      6,
+					Statement: "assignment",
+					SQL:       "total := total + 1",
+					Text:      "PL/pgSQL function count_rows() line 6 at assignment",
+				},
+			},
+		},
+		{
+			name: "statement without function",
+			where: "SQL statement \"SELECT 1/0\"\n" +
+				"while executing the query",
+			want: []Frame{
+				{Statement: "SQL statement", SQL: "SELECT 1/0", Text: "SQL statement \"SELECT 1/0\""},
+				{Text: "while executing the query"},
+			},
+		},
+		{
+			name:  "unterminated statement",
+			where: "SQL statement \"SELECT 1\nFROM t",
+			want: []Frame{
+				{Statement: "SQL statement", SQL: "SELECT 1\nFROM t", Text: "SQL statement \"SELECT 1\nFROM t"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseContext(tt.where); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseContext() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStack(t *testing.T) {
+	err := fmt.Errorf("checkout: %w", &pq.Error{Where: "PL/pgSQL function inline_code_block line 3 at RAISE"})
+	frames := Stack(err)
+	if len(frames) != 1 || frames[0].Signature() != "inline_code_block" {
+		t.Errorf("Stack() = %+v", frames)
+	}
+	if Stack(fmt.Errorf("boom")) != nil {
+		t.Errorf("Stack() of a non-pq error is not nil")
+	}
+	f := Frame{Function: "billing.charge", Args: []string{"integer", "text"}}
+	if got, want := f.Signature(), "billing.charge(integer, text)"; got != want {
+		t.Errorf("Signature() = %q, want %q", got, want)
+	}
+}
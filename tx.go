@@ -0,0 +1,96 @@
+package pqerror
+
+import (
+	"context"
+	"database/sql"
+)
+
+// IsAmbiguousCommit reports whether an error returned by COMMIT leaves the
+// outcome of the transaction unknown: the server reported
+// TransactionResolutionUnknown or StatementCompletionUnknown, or the
+// connection was lost while the commit was in flight, e.g. AdminShutdown or
+// io.EOF, see ConnFailureOf. Such a transaction may or may not have
+// committed, so it must not be retried blindly.
+//
+// The result is meaningful only for errors returned by COMMIT, as errors of
+// other statements leave the transaction uncommitted.
+func IsAmbiguousCommit(err error) bool {
+	if Catches(err, TransactionResolutionUnknown, StatementCompletionUnknown) {
+		return true
+	}
+	return ConnFailureOf(err) == ConnLost
+}
+
+// AmbiguousCommitError is returned by RunTx when the outcome of the commit of
+// a transaction is unknown, see IsAmbiguousCommit.
+type AmbiguousCommitError struct {
+	Err error
+}
+
+func (e *AmbiguousCommitError) Error() string {
+	return "pqerror: transaction may or may not have committed: " + e.Err.Error()
+}
+
+// Unwrap returns the error returned by COMMIT.
+func (e *AmbiguousCommitError) Unwrap() error {
+	return e.Err
+}
+
+// RunTx runs a function in a transaction and commits it. If the function or
+// the commit fails with an error of class ClassTransactionRollback, e.g.
+// SerializationFailure or DeadlockDetected, or with a registered code
+// declared Retryable, see Register, the transaction is retried up to
+// maxRetries times. If the outcome of the commit is unknown, RunTx returns
+// an *AmbiguousCommitError and does not retry, e.g.
+//
+//	err := pqerror.RunTx(ctx, db, nil, 3, func(tx *sql.Tx) error {
+//		_, err := tx.ExecContext(ctx, "INSERT INTO payments ...")
+//		return err
+//	})
+//	var ambiguous *pqerror.AmbiguousCommitError
+//	if errors.As(err, &ambiguous) {
+//		// Check whether the payment exists before trying again.
+//	}
+func RunTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, maxRetries int, fn func(tx *sql.Tx) error) error {
+	for attempt := 0; ; attempt++ {
+		err := runTx(ctx, db, opts, fn)
+		if err == nil || attempt >= maxRetries || !retryableTx(err) {
+			return err
+		}
+	}
+}
+
+func runTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		if IsAmbiguousCommit(err) {
+			return &AmbiguousCommitError{Err: err}
+		}
+		return err
+	}
+	return nil
+}
+
+// retryableTx reports whether a transaction failing with an error may succeed
+// when retried.
+func retryableTx(err error) bool {
+	if _, ok := err.(*AmbiguousCommitError); ok {
+		return false
+	}
+	pqerr, ok := asPqError(err)
+	if !ok || len(pqerr.Code) != 5 {
+		return false
+	}
+	if pqerr.Code.Class() == ClassTransactionRollback {
+		return pqerr.Code != StatementCompletionUnknown
+	}
+	c, ok := LookupCustom(pqerr.Code)
+	return ok && c.Retryable
+}
@@ -0,0 +1,164 @@
+package pqerror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestRetryableTx(t *testing.T) {
+	defer unregister("U0001", "U0002")
+	MustRegister(CustomCode{Code: "U0001", Retryable: true})
+	MustRegister(CustomCode{Code: "U0002"})
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("boom"), false},
+		{&pq.Error{Code: SerializationFailure}, true},
+		{fmt.Errorf("transfer: %w", &pq.Error{Code: DeadlockDetected}), true},
+		{&pq.Error{Code: StatementCompletionUnknown}, false},
+		{&pq.Error{Code: UniqueViolation}, false},
+		{&pq.Error{Code: "U0001"}, true},
+		{fmt.Errorf("transfer: %w", &pq.Error{Code: "U0001"}), true},
+		{&pq.Error{Code: "U0002"}, false},
+		{&pq.Error{Code: "U0003"}, false},
+		{&AmbiguousCommitError{Err: &pq.Error{Code: SerializationFailure}}, false},
+	}
+	for _, tt := range tests {
+		if got := retryableTx(tt.err); got != tt.want {
+			t.Errorf("retryableTx(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// fakeTxConn is a connection whose transactions fail to commit with the
+// given errors, one per transaction, and commit once they run out.
+type fakeTxConn struct {
+	commitErrs []error
+	commits    int
+}
+
+func (c *fakeTxConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *fakeTxConn) Close() error              { return nil }
+func (c *fakeTxConn) Begin() (driver.Tx, error) { return fakeTx{c}, nil }
+
+type fakeTx struct{ conn *fakeTxConn }
+
+func (tx fakeTx) Commit() error {
+	c := tx.conn
+	c.commits++
+	if c.commits <= len(c.commitErrs) {
+		return c.commitErrs[c.commits-1]
+	}
+	return nil
+}
+
+func (tx fakeTx) Rollback() error { return nil }
+
+type fakeTxConnector struct{ conn *fakeTxConn }
+
+func (c fakeTxConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c fakeTxConnector) Driver() driver.Driver                        { return nil }
+
+func TestRunTx(t *testing.T) {
+	serialization := &pq.Error{Code: SerializationFailure}
+	completionUnknown := &pq.Error{Code: StatementCompletionUnknown}
+	tests := []struct {
+		name       string
+		commitErrs []error
+		wantRuns   int
+		wantErr    error
+		ambiguous  bool
+	}{
+		{"commit", nil, 1, nil, false},
+		{"serialization failure", []error{serialization}, 2, nil, false},
+		{"serialization failures", []error{serialization, serialization, serialization, serialization}, 4, serialization, false},
+		{"EOF", []error{io.EOF}, 1, io.EOF, true},
+		{"bad connection", []error{driver.ErrBadConn}, 1, driver.ErrBadConn, true},
+		{"statement completion unknown", []error{completionUnknown}, 1, completionUnknown, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeTxConn{commitErrs: tt.commitErrs}
+			db := sql.OpenDB(fakeTxConnector{conn})
+			defer db.Close()
+
+			runs := 0
+			err := RunTx(context.Background(), db, nil, 3, func(tx *sql.Tx) error {
+				runs++
+				return nil
+			})
+			if runs != tt.wantRuns {
+				t.Errorf("RunTx() ran the function %d times, want %d", runs, tt.wantRuns)
+			}
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("RunTx() error = %v, want %v", err, tt.wantErr)
+			}
+			var ambiguous *AmbiguousCommitError
+			if got := errors.As(err, &ambiguous); got != tt.ambiguous {
+				t.Fatalf("RunTx() error %v is *AmbiguousCommitError: %v, want %v", err, got, tt.ambiguous)
+			}
+			if tt.ambiguous && ambiguous.Unwrap() != tt.wantErr {
+				t.Errorf("Unwrap() = %v, want %v", ambiguous.Unwrap(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunTxFunctionError(t *testing.T) {
+	db := sql.OpenDB(fakeTxConnector{&fakeTxConn{}})
+	defer db.Close()
+	deadlock := fmt.Errorf("transfer: %w", &pq.Error{Code: DeadlockDetected})
+	unique := &pq.Error{Code: UniqueViolation}
+	errs := []error{deadlock, unique}
+	runs := 0
+	err := RunTx(context.Background(), db, nil, 3, func(tx *sql.Tx) error {
+		runs++
+		return errs[runs-1]
+	})
+	if runs != 2 || err != unique {
+		t.Errorf("RunTx() = %v after %d runs, want %v after 2", err, runs, unique)
+	}
+}
+
+func TestIsAmbiguousCommit(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{io.EOF, true},
+		{driver.ErrBadConn, true},
+		{&pq.Error{Code: AdminShutdown}, true},
+		{&pq.Error{Code: TransactionResolutionUnknown}, true},
+		{&pq.Error{Code: StatementCompletionUnknown}, true},
+		{fmt.Errorf("commit: %w", &pq.Error{Code: StatementCompletionUnknown}), true},
+		{&pq.Error{Code: SerializationFailure}, false},
+		{&pq.Error{Code: UniqueViolation}, false},
+	}
+	for _, tt := range tests {
+		if got := IsAmbiguousCommit(tt.err); got != tt.want {
+			t.Errorf("IsAmbiguousCommit(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestAmbiguousCommitError(t *testing.T) {
+	err := fmt.Errorf("pay: %w", &AmbiguousCommitError{Err: io.EOF})
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("errors.Is(%v, io.EOF) = false", err)
+	}
+	if want := "pay: pqerror: transaction may or may not have committed: EOF"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err, want)
+	}
+}